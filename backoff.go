@@ -1,44 +1,179 @@
 package maps
 
 import (
+	"context"
+	"errors"
 	"math/rand"
 	"net/http"
+	"strconv"
 	"time"
 )
 
-type backoff struct {
+// RetryPolicy decides whether and when a failed request should be retried.
+//
+// Implementations must be safe for concurrent use by multiple goroutines; any state about a particular request is passed to Backoff.
+type RetryPolicy interface {
+	// Backoff is called after the given attempt (starting at 1) failed, with either the response received or the error returned.
+	// Requests which fail with an API-level status are reported with a nil response and an APIError.
+	//
+	// It reports how long to wait before trying again, and whether the request should be retried at all.
+	Backoff(attempt int, resp *http.Response, err error) (wait time.Duration, retry bool)
+}
+
+// ExponentialBackoff is a RetryPolicy that waits exponentially longer between each attempt.
+//
+// If a response includes a Retry-After header, that delay is used instead.
+type ExponentialBackoff struct {
+	// MaxAttempts is the maximum number of attempts to make, including the first. If zero, 5 attempts are made.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, which is doubled for each subsequent retry. If zero or negative, one second is used.
+	BaseDelay time.Duration
+
+	// MaxDelay is the maximum delay before any retry, before jitter is added. If zero or negative, one minute is used.
+	MaxDelay time.Duration
+
+	// Jitter returns a random duration to add to each delay.
+	//
+	// If nil, a random duration between -BaseDelay/2 and +BaseDelay/2 is used.
+	Jitter func() time.Duration
+
+	// Retryable reports whether a failed attempt should be retried.
+	//
+	// If nil, DefaultRetryable is used.
+	Retryable func(resp *http.Response, err error) bool
+}
+
+// Backoff implements RetryPolicy.
+func (b ExponentialBackoff) Backoff(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	max := b.MaxAttempts
+	if max == 0 {
+		max = 5
+	}
+	retryable := b.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	if attempt >= max || !retryable(resp, err) {
+		return 0, false
+	}
+	if d, ok := retryAfter(resp); ok {
+		return d, true
+	}
+	base := b.BaseDelay
+	if base <= 0 {
+		base = time.Second
+	}
+	maxDelay := b.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = time.Minute
+	}
+	// Double the delay one attempt at a time, so that it is capped before it can overflow.
+	wait := base
+	for i := 1; i < attempt && wait < maxDelay; i++ {
+		wait *= 2
+	}
+	if wait > maxDelay {
+		wait = maxDelay
+	}
+	if b.Jitter != nil {
+		wait += b.Jitter()
+	} else {
+		wait += time.Duration(rand.Int63n(int64(base))) - base/2
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// DefaultRetryable reports whether a failed attempt should be retried: when the server could not be reached,
+// when it responded with a server error or asked the client to slow down, or when the API reported
+// StatusOverQueryLimit or StatusUnknownError.
+//
+// Errors which have a Retryable method, such as APIError, are retried only if it returns true.
+func DefaultRetryable(resp *http.Response, err error) bool {
+	var r interface{ Retryable() bool }
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// retryAfter returns the delay requested by the response's Retry-After header, if any.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	h := resp.Header.Get("Retry-After")
+	if h == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(h); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// RetryTransport is an http.RoundTripper that retries failed requests according to a RetryPolicy.
+//
+// It keeps no state between requests, so it is safe for concurrent use if its Transport and Policy are.
+type RetryTransport struct {
+	// Transport is used to make each attempt. If nil, http.DefaultTransport is used.
 	Transport http.RoundTripper
-	MaxTries  int
-	tries     int
-	sleep     func(time.Duration)
-}
-
-func (bt *backoff) RoundTrip(req *http.Request) (*http.Response, error) {
-	if bt.MaxTries == 0 {
-		bt.MaxTries = 5
-	}
-	if bt.sleep == nil {
-		bt.sleep = time.Sleep
-	}
-	wait := time.Second
-	var err error
-	for ; bt.tries < bt.MaxTries; bt.tries++ {
-		var resp *http.Response
-		resp, err = bt.Transport.RoundTrip(req)
-		if err != nil {
-			// fallthrough, retry
-		} else if resp.StatusCode >= http.StatusInternalServerError {
-			err = HTTPError{resp}
-		} else {
-			return resp, nil
+
+	// Policy decides whether and when to retry failed attempts. If nil, ExponentialBackoff{} is used.
+	Policy RetryPolicy
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t := rt.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	p := rt.Policy
+	if p == nil {
+		p = ExponentialBackoff{}
+	}
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		resp, err := t.RoundTrip(req)
+		if err != nil && ctx.Err() != nil {
+			// the request failed because the context is done, don't retry
+			return nil, err
 		}
-		if bt.tries == bt.MaxTries-1 {
-			// last try failed, just give up
-			continue
+		wait, retry := p.Backoff(attempt, resp, err)
+		if !retry {
+			return resp, err
 		}
-		bt.sleep(wait)
-		jitter := time.Duration(rand.Intn(1000))*time.Millisecond - 500*time.Millisecond // jitter is +/- .5s
-		wait = wait*2 + jitter
+		if resp != nil {
+			err = newHTTPError(resp)
+		}
+		if serr := sleep(ctx, wait); serr != nil {
+			return nil, RetryCanceledError{Attempts: attempt, Err: serr, LastErr: err}
+		}
+	}
+}
+
+// sleep waits for the duration d to elapse, returning early with the context's error if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return nil, err
 }
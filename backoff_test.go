@@ -1,36 +1,150 @@
 package maps
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
+func noJitter() time.Duration { return 0 }
+
 func TestBackoff(t *testing.T) {
+	var hits int32
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
 		http.Error(w, "fail", http.StatusInternalServerError)
 	}))
 	defer s.Close()
 	tries := 10
-	sleeps := 0
-	bt := &backoff{
-		MaxTries:  tries,
-		Transport: http.DefaultTransport,
-		sleep: func(d time.Duration) {
-			sleeps += 1
-			t.Logf("sleeping %s", d)
-		},
-	}
-	c := &http.Client{Transport: bt}
-	r, _ := http.NewRequest("GET", s.URL, nil)
-	if _, err := c.Do(r); err == nil {
-		t.Errorf("expected error")
-	}
-	if sleeps != tries-1 {
-		t.Errorf("unexpected # of calls to sleep, got %d, want %d", tries-1, sleeps)
-	}
-	if bt.tries != tries {
-		t.Errorf("got %d tries, want %d", bt.tries, tries)
+	var waits []time.Duration
+	p := ExponentialBackoff{MaxAttempts: tries, BaseDelay: time.Millisecond, Jitter: noJitter}
+	c := &http.Client{Transport: &RetryTransport{Policy: recordingPolicy{p, &waits}}}
+	resp, err := c.Get(s.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusInternalServerError)
+	}
+	if int(hits) != tries {
+		t.Errorf("got %d tries, want %d", hits, tries)
+	}
+	if len(waits) != tries {
+		t.Fatalf("unexpected # of calls to Backoff, got %d, want %d", len(waits), tries)
+	}
+	for i, w := range waits[:tries-1] {
+		if exp := time.Millisecond << uint(i); w != exp {
+			t.Errorf("wait %d: got %s, want %s", i, w, exp)
+		}
+	}
+}
+
+type recordingPolicy struct {
+	RetryPolicy
+	waits *[]time.Duration
+}
+
+func (p recordingPolicy) Backoff(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	w, ok := p.RetryPolicy.Backoff(attempt, resp, err)
+	*p.waits = append(*p.waits, w)
+	return w, ok
+}
+
+func TestBackoffLimits(t *testing.T) {
+	err := errors.New("fail")
+
+	// Delays are capped at MaxDelay, even after enough attempts to overflow a Duration.
+	p := ExponentialBackoff{MaxAttempts: 1000, BaseDelay: time.Second, MaxDelay: 10 * time.Second, Jitter: noJitter}
+	for _, c := range []struct {
+		attempt int
+		exp     time.Duration
+	}{{1, time.Second}, {3, 4 * time.Second}, {4, 8 * time.Second}, {5, 10 * time.Second}, {100, 10 * time.Second}, {999, 10 * time.Second}} {
+		if d, ok := p.Backoff(c.attempt, nil, err); !ok || d != c.exp {
+			t.Errorf("attempt %d: got %s, %t; want %s, true", c.attempt, d, ok, c.exp)
+		}
+	}
+	p.MaxDelay = 0
+	if d, _ := p.Backoff(999, nil, err); d != time.Minute {
+		t.Errorf("got %s, want default MaxDelay of 1m", d)
+	}
+
+	// A negative BaseDelay is treated as the default, with default jitter.
+	p = ExponentialBackoff{BaseDelay: -time.Second}
+	for i := 0; i < 100; i++ {
+		if d, ok := p.Backoff(1, nil, err); !ok || d < time.Second/2 || d > 3*time.Second/2 {
+			t.Fatalf("got %s, %t; want 1s±500ms, true", d, ok)
+		}
+	}
+}
+
+func TestBackoffConcurrent(t *testing.T) {
+	var hits int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.Error(w, "fail", http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+	tries, workers := 3, 10
+	c := &http.Client{Transport: &RetryTransport{Policy: ExponentialBackoff{MaxAttempts: tries, BaseDelay: time.Millisecond, Jitter: noJitter}}}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.Get(s.URL)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if int(hits) != tries*workers {
+		t.Errorf("got %d tries, want %d", hits, tries*workers)
+	}
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"3"}}}
+	w, ok := ExponentialBackoff{}.Backoff(1, resp, nil)
+	if !ok {
+		t.Fatalf("expected retry")
+	}
+	if w != 3*time.Second {
+		t.Errorf("got wait %s, want %s", w, 3*time.Second)
+	}
+
+	resp = &http.Response{StatusCode: http.StatusBadRequest}
+	if _, ok := (ExponentialBackoff{}).Backoff(1, resp, nil); ok {
+		t.Errorf("unexpected retry for status %d", resp.StatusCode)
+	}
+}
+
+func TestBackoffCanceled(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "fail", http.StatusInternalServerError)
+	}))
+	defer s.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	c := &http.Client{Transport: &RetryTransport{Policy: ExponentialBackoff{BaseDelay: time.Hour, Jitter: noJitter}}}
+	r, _ := http.NewRequestWithContext(ctx, "GET", s.URL, nil)
+	_, err := c.Do(r)
+	var rce RetryCanceledError
+	if !errors.As(err, &rce) {
+		t.Fatalf("got error %v, want RetryCanceledError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if rce.Attempts != 1 {
+		t.Errorf("got %d attempts, want 1", rce.Attempts)
 	}
 }
@@ -0,0 +1,166 @@
+package maps
+
+import (
+	"math"
+	"sort"
+)
+
+// NewBounds returns the smallest bounding box containing all of the locations.
+//
+// If the locations are spread across the antimeridian, the returned Bounds may cross it; see Bounds.CrossesAntimeridian.
+// If no locations are given, the returned Bounds is empty, and can be extended to contain locations; see Bounds.IsEmpty.
+func NewBounds(ll ...LatLng) Bounds {
+	if len(ll) == 0 {
+		return Bounds{Northeast: LatLng{Lat: -90}, Southwest: LatLng{Lat: 90}}
+	}
+	b := Bounds{Northeast: ll[0], Southwest: ll[0]}
+	lngs := make([]float64, len(ll))
+	for i, l := range ll {
+		b.Northeast.Lat = math.Max(b.Northeast.Lat, l.Lat)
+		b.Southwest.Lat = math.Min(b.Southwest.Lat, l.Lat)
+		lngs[i] = normalizeLng(l.Lng)
+	}
+	// The smallest longitude span excludes the largest gap between consecutive longitudes, going around the world.
+	sort.Float64s(lngs)
+	gap, east := lngs[0]+360-lngs[len(lngs)-1], len(lngs)-1
+	for i := 1; i < len(lngs); i++ {
+		if g := lngs[i] - lngs[i-1]; g > gap {
+			gap, east = g, i-1
+		}
+	}
+	b.Northeast.Lng = lngs[east]
+	b.Southwest.Lng = lngs[(east+1)%len(lngs)]
+	return b
+}
+
+// IsEmpty reports whether the bounding box is empty, i.e., whether its Southwest latitude is greater than its Northeast latitude,
+// as for the Bounds returned by NewBounds with no locations.
+//
+// An empty bounding box does not contain or intersect anything, and extending it or taking its union with another
+// bounding box results in a bounding box containing only the new location or bounding box.
+func (b Bounds) IsEmpty() bool {
+	return b.Southwest.Lat > b.Northeast.Lat
+}
+
+// CrossesAntimeridian reports whether the bounding box crosses the 180th meridian, i.e., whether its Southwest longitude is greater than its Northeast longitude.
+func (b Bounds) CrossesAntimeridian() bool {
+	return b.Southwest.Lng > b.Northeast.Lng
+}
+
+// lngSpan returns the width of the bounding box in degrees of longitude, between 0 and 360.
+func (b Bounds) lngSpan() float64 {
+	if b.Southwest.Lng == -180 && b.Northeast.Lng == 180 {
+		return 360
+	}
+	return math.Mod(b.Northeast.Lng-b.Southwest.Lng+360, 360)
+}
+
+// lngOffset returns how many degrees east of the bounding box's west edge the longitude is, between 0 and 360.
+func (b Bounds) lngOffset(lng float64) float64 {
+	return math.Mod(math.Mod(lng-b.Southwest.Lng, 360)+360, 360)
+}
+
+// Span returns the height and width of the bounding box, in degrees of latitude and longitude.
+func (b Bounds) Span() LatLng {
+	return LatLng{b.Northeast.Lat - b.Southwest.Lat, b.lngSpan()}
+}
+
+// Center returns the location at the center of the bounding box.
+func (b Bounds) Center() LatLng {
+	return LatLng{
+		(b.Northeast.Lat + b.Southwest.Lat) / 2,
+		normalizeLng(b.Southwest.Lng + b.lngSpan()/2),
+	}
+}
+
+// Contains reports whether the location is within the bounding box.
+func (b Bounds) Contains(ll LatLng) bool {
+	if b.IsEmpty() || ll.Lat < b.Southwest.Lat || ll.Lat > b.Northeast.Lat {
+		return false
+	}
+	return b.lngOffset(ll.Lng) <= b.lngSpan() || b.lngSpan() == 360
+}
+
+// containsLngs reports whether the longitudes of o are within the longitudes of b.
+func (b Bounds) containsLngs(o Bounds) bool {
+	if b.lngSpan() == 360 {
+		return true
+	}
+	off := b.lngOffset(o.Southwest.Lng)
+	return off+o.lngSpan() <= b.lngSpan()
+}
+
+// Intersects reports whether the bounding boxes overlap.
+func (b Bounds) Intersects(o Bounds) bool {
+	if b.IsEmpty() || o.IsEmpty() {
+		return false
+	}
+	if b.Southwest.Lat > o.Northeast.Lat || o.Southwest.Lat > b.Northeast.Lat {
+		return false
+	}
+	return b.lngOffset(o.Southwest.Lng) <= b.lngSpan() || o.lngOffset(b.Southwest.Lng) <= o.lngSpan()
+}
+
+// Union returns the smallest bounding box containing both bounding boxes.
+func (b Bounds) Union(o Bounds) Bounds {
+	if b.IsEmpty() {
+		return o
+	}
+	if o.IsEmpty() {
+		return b
+	}
+	u := Bounds{
+		Northeast: LatLng{Lat: math.Max(b.Northeast.Lat, o.Northeast.Lat)},
+		Southwest: LatLng{Lat: math.Min(b.Southwest.Lat, o.Southwest.Lat)},
+	}
+	// Choose the narrowest combination of edges which contains the longitudes of both.
+	span := 360.0
+	u.Southwest.Lng, u.Northeast.Lng = -180, 180
+	for _, w := range []float64{b.Southwest.Lng, o.Southwest.Lng} {
+		for _, e := range []float64{b.Northeast.Lng, o.Northeast.Lng} {
+			c := Bounds{Northeast: LatLng{Lng: e}, Southwest: LatLng{Lng: w}}
+			if s := c.lngSpan(); s < span && c.containsLngs(b) && c.containsLngs(o) {
+				span, u.Southwest.Lng, u.Northeast.Lng = s, w, e
+			}
+		}
+	}
+	return u
+}
+
+// Extend returns the smallest bounding box containing both the bounding box and the location.
+func (b Bounds) Extend(ll LatLng) Bounds {
+	return b.Union(Bounds{Northeast: ll, Southwest: ll})
+}
+
+// PadMeters returns the bounding box expanded by the given distance in meters on each side.
+//
+// The longitude padding is computed at the latitude furthest from the equator, where a degree of longitude is narrowest,
+// so that the distance is at least the padding everywhere along each side.
+func (b Bounds) PadMeters(m float64) Bounds {
+	dLat := degrees(m / EarthRadius)
+	maxLat := math.Min(math.Max(math.Abs(b.Northeast.Lat), math.Abs(b.Southwest.Lat)), 89.999999)
+	dLng := degrees(m / (EarthRadius * math.Cos(radians(maxLat))))
+	return b.pad(dLat, dLng)
+}
+
+// PadPercent returns the bounding box expanded on each side by the given percentage of its height and width.
+//
+// For example, PadPercent(10) expands a bounding box 100 meters wide by 10 meters on each side, resulting in a bounding box 120 meters wide.
+func (b Bounds) PadPercent(p float64) Bounds {
+	s := b.Span()
+	return b.pad(s.Lat*p/100, s.Lng*p/100)
+}
+
+func (b Bounds) pad(dLat, dLng float64) Bounds {
+	if b.IsEmpty() {
+		return b
+	}
+	p := Bounds{
+		Northeast: LatLng{math.Min(90, b.Northeast.Lat+dLat), normalizeLng(b.Northeast.Lng + dLng)},
+		Southwest: LatLng{math.Max(-90, b.Southwest.Lat-dLat), normalizeLng(b.Southwest.Lng - dLng)},
+	}
+	if b.lngSpan()+2*dLng >= 360 {
+		p.Southwest.Lng, p.Northeast.Lng = -180, 180
+	}
+	return p
+}
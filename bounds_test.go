@@ -0,0 +1,117 @@
+package maps
+
+import "testing"
+
+func TestBounds(t *testing.T) {
+	b := NewBounds(LatLng{40.70, -74.02}, LatLng{40.82, -73.93}, LatLng{40.75, -73.98})
+	if exp := (Bounds{Northeast: LatLng{40.82, -73.93}, Southwest: LatLng{40.70, -74.02}}); b != exp {
+		t.Errorf("got %v, want %v", b, exp)
+	}
+	if b.CrossesAntimeridian() {
+		t.Errorf("bounds %v unexpectedly cross antimeridian", b)
+	}
+	if c := b.Center(); !near(c.Lat, 40.76, 1e-9) || !near(c.Lng, -73.975, 1e-9) {
+		t.Errorf("got center %v", c)
+	}
+	if s := b.Span(); !near(s.Lat, 0.12, 1e-9) || !near(s.Lng, 0.09, 1e-9) {
+		t.Errorf("got span %v", s)
+	}
+	if !b.Contains(LatLng{40.75, -74}) || b.Contains(LatLng{40.75, -73}) || b.Contains(LatLng{41, -74}) {
+		t.Errorf("unexpected containment for %v", b)
+	}
+
+	// Fiji spans the antimeridian.
+	fiji := NewBounds(LatLng{-16, 178}, LatLng{-19, -179}, LatLng{-17, 179.5})
+	if exp := (Bounds{Northeast: LatLng{-16, -179}, Southwest: LatLng{-19, 178}}); fiji != exp {
+		t.Errorf("got %v, want %v", fiji, exp)
+	}
+	if !fiji.CrossesAntimeridian() {
+		t.Errorf("bounds %v do not cross antimeridian", fiji)
+	}
+	if s := fiji.Span(); !near(s.Lng, 3, 1e-9) {
+		t.Errorf("got span %v, want 3 degrees of longitude", s)
+	}
+	if c := fiji.Center(); !near(c.Lng, 179.5, 1e-9) {
+		t.Errorf("got center %v, want longitude 179.5", c)
+	}
+	if !fiji.Contains(LatLng{-17, 180}) || !fiji.Contains(LatLng{-17, -179.5}) || fiji.Contains(LatLng{-17, 0}) {
+		t.Errorf("unexpected containment for %v", fiji)
+	}
+
+	samoa := Bounds{Northeast: LatLng{-13, -171}, Southwest: LatLng{-15, -173}}
+	if fiji.Intersects(samoa) || samoa.Intersects(fiji) || !fiji.Intersects(fiji.Extend(LatLng{-15, -172})) {
+		t.Errorf("unexpected intersection")
+	}
+	if !fiji.Intersects(Bounds{Northeast: LatLng{0, 179}, Southwest: LatLng{-17, 170}}) {
+		t.Errorf("expected intersection across antimeridian")
+	}
+	u := fiji.Union(samoa)
+	if exp := (Bounds{Northeast: LatLng{-13, -171}, Southwest: LatLng{-19, 178}}); u != exp {
+		t.Errorf("got union %v, want %v", u, exp)
+	}
+	if u := b.Union(fiji); u.lngSpan() >= 180 {
+		t.Errorf("got union %v, want narrowest span", u)
+	}
+	if e := b.Extend(LatLng{40.75, -73.99}); e != b {
+		t.Errorf("extending by a contained point changed bounds to %v", e)
+	}
+
+	p := b.PadMeters(1000)
+	if d := p.Northeast.Distance(LatLng{b.Northeast.Lat, p.Northeast.Lng}); !near(d, 1000, 1e-6) {
+		t.Errorf("got north padding %fm, want 1000m", d)
+	}
+	if d := (LatLng{b.Northeast.Lat, b.Northeast.Lng}).Distance(LatLng{b.Northeast.Lat, p.Northeast.Lng}); d < 999 {
+		t.Errorf("got east padding %fm, want at least 1000m", d)
+	}
+	p = fiji.PadPercent(50)
+	if exp := (Bounds{Northeast: LatLng{-14.5, -177.5}, Southwest: LatLng{-20.5, 176.5}}); p != exp {
+		t.Errorf("got %v, want %v", p, exp)
+	}
+	if p := fiji.PadPercent(10000); p.Southwest.Lng != -180 || p.Northeast.Lng != 180 || p.Northeast.Lat != 90 {
+		t.Errorf("got %v, want entire world", p)
+	}
+}
+
+func TestEmptyBounds(t *testing.T) {
+	empty := NewBounds()
+	if !empty.IsEmpty() {
+		t.Error("NewBounds() is not empty")
+	}
+	if empty.Contains(LatLng{0, 0}) {
+		t.Error("empty bounds contains 0,0")
+	}
+
+	// Bounds containing only the origin are not empty.
+	origin := NewBounds(LatLng{0, 0})
+	if origin.IsEmpty() || (Bounds{}).IsEmpty() {
+		t.Errorf("bounds %v containing only 0,0 is empty", origin)
+	}
+	if !origin.Contains(LatLng{0, 0}) || !empty.Extend(LatLng{0, 0}).Contains(LatLng{0, 0}) {
+		t.Errorf("bounds %v does not contain 0,0", origin)
+	}
+	if b := origin.Extend(LatLng{1, 1}); b != (Bounds{Northeast: LatLng{1, 1}}) {
+		t.Errorf("got %v, want bounds from 0,0 to 1,1", b)
+	}
+	b := NewBounds(LatLng{40, -74})
+	if b.IsEmpty() || empty.Intersects(b) || b.Intersects(empty) {
+		t.Errorf("unexpected intersection of %v with empty bounds", b)
+	}
+
+	// Extending empty bounds starts from the first location, not from 0,0.
+	b = empty.Extend(LatLng{40, -74}).Extend(LatLng{41, -73})
+	if exp := (Bounds{Northeast: LatLng{41, -73}, Southwest: LatLng{40, -74}}); b != exp {
+		t.Errorf("got %v, want %v", b, exp)
+	}
+	if b.Contains(LatLng{20, -37}) {
+		t.Errorf("%v unexpectedly contains 20,-37", b)
+	}
+	if u := empty.Union(b); u != b {
+		t.Errorf("got union %v, want %v", u, b)
+	}
+	if u := b.Union(empty); u != b {
+		t.Errorf("got union %v, want %v", u, b)
+	}
+	if p := empty.PadMeters(1000); !p.IsEmpty() {
+		t.Errorf("got padded empty bounds %v, want empty", p)
+	}
+}
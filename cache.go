@@ -0,0 +1,243 @@
+package maps
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL specifies how long responses from each Service are cached by a Cache created by NewCache.
+//
+// Responses from services whose results depend on current conditions, such as traffic, are not cached.
+var DefaultCacheTTL = map[Service]time.Duration{
+	ServiceGeocode:    24 * time.Hour,
+	ServiceElevation:  24 * time.Hour,
+	ServiceTimeZone:   24 * time.Hour,
+	ServiceStaticMap:  24 * time.Hour,
+	ServiceStreetView: 24 * time.Hour,
+}
+
+// CacheStorage stores cached response bodies.
+//
+// Implementations must be safe for concurrent use by multiple goroutines.
+type CacheStorage interface {
+	// Get returns the value stored for key, and whether a value was found that has not expired.
+	Get(key string) ([]byte, bool)
+
+	// Set stores the value for key, to expire after ttl.
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// Cache caches successful responses, keyed on the request URL without credentials.
+//
+// A Cache is safe for concurrent use by multiple goroutines, and may be shared by multiple Clients.
+type Cache struct {
+	storage CacheStorage
+	ttl     map[Service]time.Duration
+
+	mu    sync.Mutex
+	stats map[Service]CacheStats
+}
+
+// CacheStats describes the usage of a Cache.
+type CacheStats struct {
+	// Hits is the number of requests which were served from the cache.
+	Hits int64
+
+	// Misses is the number of cacheable requests which were not found in the cache.
+	Misses int64
+}
+
+// NewCache returns a Cache which stores responses in the provided CacheStorage, for the durations specified by DefaultCacheTTL.
+func NewCache(s CacheStorage) *Cache {
+	c := &Cache{
+		storage: s,
+		ttl:     map[Service]time.Duration{},
+		stats:   map[Service]CacheStats{},
+	}
+	for svc, ttl := range DefaultCacheTTL {
+		c.ttl[svc] = ttl
+	}
+	return c
+}
+
+// SetTTL sets how long responses from the given Service are cached. If ttl is zero or negative, responses are not cached.
+func (c *Cache) SetTTL(svc Service, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl[svc] = ttl
+}
+
+// Stats returns the usage of the cache by each Service.
+func (c *Cache) Stats() map[Service]CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := make(map[Service]CacheStats, len(c.stats))
+	for svc, s := range c.stats {
+		m[svc] = s
+	}
+	return m
+}
+
+// get returns the cached response for key, if svc is cacheable and the response is found.
+func (c *Cache) get(svc Service, key string) ([]byte, bool) {
+	if c == nil || c.ttlFor(svc) <= 0 {
+		return nil, false
+	}
+	b, ok := c.storage.Get(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats[svc]
+	if ok {
+		s.Hits++
+	} else {
+		s.Misses++
+	}
+	c.stats[svc] = s
+	return b, ok
+}
+
+// set caches the response for key, if svc is cacheable.
+func (c *Cache) set(svc Service, key string, b []byte) {
+	if c == nil {
+		return
+	}
+	if ttl := c.ttlFor(svc); ttl > 0 {
+		c.storage.Set(key, b, ttl)
+	}
+}
+
+func (c *Cache) ttlFor(svc Service) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ttl[svc]
+}
+
+// MemoryCache is a CacheStorage which stores values in memory, evicting the least recently used values once it is full.
+type MemoryCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns a MemoryCache which stores up to size values.
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{
+		size:    size,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get implements CacheStorage.
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*memoryEntry)
+	if !m.now().Before(e.expires) {
+		m.lru.Remove(el)
+		delete(m.entries, key)
+		return nil, false
+	}
+	m.lru.MoveToFront(el)
+	return e.value, true
+}
+
+// Set implements CacheStorage.
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	expires := m.now().Add(ttl)
+	if el, ok := m.entries[key]; ok {
+		e := el.Value.(*memoryEntry)
+		e.value, e.expires = value, expires
+		m.lru.MoveToFront(el)
+		return
+	}
+	m.entries[key] = m.lru.PushFront(&memoryEntry{key, value, expires})
+	for m.lru.Len() > m.size {
+		el := m.lru.Back()
+		m.lru.Remove(el)
+		delete(m.entries, el.Value.(*memoryEntry).key)
+	}
+}
+
+// FileCache is a CacheStorage which stores values as files in a directory.
+//
+// Errors reading or writing files are treated as cache misses.
+type FileCache struct {
+	dir string
+	now func() time.Time
+}
+
+// NewFileCache returns a FileCache which stores values in dir, creating it if necessary.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir, now: time.Now}, nil
+}
+
+func (f *FileCache) path(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(h[:]))
+}
+
+// Get implements CacheStorage.
+func (f *FileCache) Get(key string) ([]byte, bool) {
+	p := f.path(key)
+	b, err := ioutil.ReadFile(p)
+	if err != nil || len(b) < 8 {
+		return nil, false
+	}
+	// Each file contains the expiry time in Unix nanoseconds, followed by the value.
+	expires := time.Unix(0, int64(binary.BigEndian.Uint64(b)))
+	if !f.now().Before(expires) {
+		os.Remove(p)
+		return nil, false
+	}
+	return b[8:], true
+}
+
+// Set implements CacheStorage.
+func (f *FileCache) Set(key string, value []byte, ttl time.Duration) {
+	b := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(b, uint64(f.now().Add(ttl).UnixNano()))
+	copy(b[8:], value)
+
+	// Write to a temporary file and rename it, so that concurrent readers never see a partial value.
+	tmp, err := ioutil.TempFile(f.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), f.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,102 @@
+package maps
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/imjasonh/staticmaps/mapstest"
+)
+
+func TestMemoryCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	m := NewMemoryCache(2)
+	m.now = func() time.Time { return now }
+	m.Set("a", []byte("a"), time.Minute)
+	m.Set("b", []byte("b"), time.Hour)
+	m.Get("a") // a is now more recently used than b
+	m.Set("c", []byte("c"), time.Hour)
+	if _, ok := m.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if v, ok := m.Get(k); !ok || string(v) != k {
+			t.Errorf("got %q, %t for %s, want %q, true", v, ok, k, k)
+		}
+	}
+	now = now.Add(time.Minute)
+	if _, ok := m.Get("a"); ok {
+		t.Errorf("expected a to be expired")
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	f, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.now = func() time.Time { return now }
+	f.Set("https://maps.googleapis.com/maps/api/geocode/json?address=NYC", []byte("value"), time.Minute)
+	if v, ok := f.Get("https://maps.googleapis.com/maps/api/geocode/json?address=NYC"); !ok || string(v) != "value" {
+		t.Errorf("got %q, %t, want %q, true", v, ok, "value")
+	}
+	if _, ok := f.Get("missing"); ok {
+		t.Errorf("unexpected value for missing key")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := f.Get("https://maps.googleapis.com/maps/api/geocode/json?address=NYC"); ok {
+		t.Errorf("expected value to be expired")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	cache := NewCache(NewMemoryCache(10))
+	c := fakeClient(t, WithCache(cache))
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := c.Geocode(ctx, &GeocodeOpts{Address: "New York"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := c.Directions(ctx, Address("New York"), Address("Boston"), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		img, err := c.StaticMap(ctx, Size{1, 1}, &StaticMapOpts{Center: Address("New York")})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if b, _ := ioutil.ReadAll(img); len(b) == 0 {
+			t.Errorf("got empty image")
+		}
+		img.Close()
+	}
+	// Unsuccessful responses are not cached.
+	fake.Enqueue(mapstest.Geocode, mapstest.Status(StatusZeroResults))
+	if _, err := c.Geocode(ctx, &GeocodeOpts{Address: "Nowhere"}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, err := c.Geocode(ctx, &GeocodeOpts{Address: "Nowhere"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for endpoint, exp := range map[string]int{
+		mapstest.Geocode:    3,
+		mapstest.Directions: 2,
+		mapstest.StaticMap:  1,
+	} {
+		if n := len(fake.Requests(endpoint)); n != exp {
+			t.Errorf("got %d requests for %s, want %d", n, endpoint, exp)
+		}
+	}
+	stats := cache.Stats()
+	if s := stats[ServiceGeocode]; s.Hits != 1 || s.Misses != 3 {
+		t.Errorf("got geocode stats %+v, want 1 hit and 3 misses", s)
+	}
+	if s := stats[ServiceStaticMap]; s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("got staticmap stats %+v, want 1 hit and 1 miss", s)
+	}
+	if _, ok := stats[ServiceDirections]; ok {
+		t.Errorf("unexpected stats for uncached service")
+	}
+}
@@ -0,0 +1,227 @@
+package maps
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client is a client for the Google Maps web services.
+//
+// A Client is safe for concurrent use by multiple goroutines.
+type Client struct {
+	key        string
+	clientID   string
+	workKey    []byte
+	workKeyErr error
+	secret     []byte
+	httpClient *http.Client
+	baseURL    string
+	serviceURL map[Service]string
+	userAgent  string
+	channel    string
+	retry      RetryPolicy
+	limiter    *RateLimiter
+	cache      *Cache
+}
+
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*Client) error
+
+// NewClient returns a new Client configured by the provided options.
+func NewClient(opts ...ClientOption) (*Client, error) {
+	c := &Client{baseURL: baseURL}
+	for _, o := range opts {
+		if err := o(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// WithAPIKey configures the Client to authenticate requests with the provided API key.
+func WithAPIKey(key string) ClientOption {
+	return func(c *Client) error {
+		c.key = key
+		return nil
+	}
+}
+
+// WithClientID configures the Client to authenticate requests with the provided Google Maps API for Work client ID,
+// and to sign requests with the provided private key.
+//
+// An error is returned if the private key is not valid URL-safe base64.
+func WithClientID(clientID, privateKey string) ClientOption {
+	return func(c *Client) error {
+		c.setWorkCredentials(clientID, privateKey)
+		return c.workKeyErr
+	}
+}
+
+// WithSigningSecret configures the Client to sign StaticMap and StreetView requests with the provided URL signing secret,
+// in addition to authenticating them with the API key provided by WithAPIKey.
+//
+// An error is returned if the secret is not valid URL-safe base64.
+//
+// See https://developers.google.com/maps/documentation/maps-static/digital-signature
+func WithSigningSecret(secret string) ClientOption {
+	return func(c *Client) error {
+		key, err := decodeKey(secret)
+		if err != nil {
+			return fmt.Errorf("invalid URL signing secret: %v", err)
+		}
+		c.secret = key
+		return nil
+	}
+}
+
+// WithHTTPClient configures the Client to make requests using the provided http.Client.
+//
+// If no http.Client is provided, a default client is used which retries failed requests.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) error {
+		c.httpClient = hc
+		return nil
+	}
+}
+
+// WithRetryPolicy configures the Client to retry failed requests according to the provided RetryPolicy.
+//
+// If no RetryPolicy is provided, ExponentialBackoff{} is used. The policy is always used to retry API-level statuses such as StatusOverQueryLimit,
+// but only used to retry failed HTTP requests if no http.Client is provided with WithHTTPClient;
+// to retry HTTP requests made with a custom http.Client, use a RetryTransport as its Transport.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) error {
+		c.retry = p
+		return nil
+	}
+}
+
+// WithRateLimiter configures the Client to wait for the provided RateLimiter before each request.
+//
+// A RateLimiter may be shared by multiple Clients which share a quota.
+func WithRateLimiter(rl *RateLimiter) ClientOption {
+	return func(c *Client) error {
+		c.limiter = rl
+		return nil
+	}
+}
+
+// WithCache configures the Client to cache successful responses in the provided Cache.
+func WithCache(cache *Cache) ClientOption {
+	return func(c *Client) error {
+		c.cache = cache
+		return nil
+	}
+}
+
+// WithBaseURL configures the Client to send requests to the provided base URL instead of https://maps.googleapis.com/maps/api/
+//
+// This applies to all services except ServiceRoads, whose base URL can be configured with WithServiceBaseURL.
+func WithBaseURL(u string) ClientOption {
+	return func(c *Client) error {
+		u, err := normalizeBaseURL(u)
+		if err != nil {
+			return err
+		}
+		c.baseURL = u
+		return nil
+	}
+}
+
+// WithServiceBaseURL configures the Client to send requests for the given Service to the provided base URL.
+//
+// This takes precedence over WithBaseURL, and is useful to direct requests to a local server in tests.
+func WithServiceBaseURL(svc Service, u string) ClientOption {
+	return func(c *Client) error {
+		u, err := normalizeBaseURL(u)
+		if err != nil {
+			return err
+		}
+		if c.serviceURL == nil {
+			c.serviceURL = map[Service]string{}
+		}
+		c.serviceURL[svc] = u
+		return nil
+	}
+}
+
+func normalizeBaseURL(u string) (string, error) {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return "", err
+	}
+	if !pu.IsAbs() {
+		return "", fmt.Errorf("base URL %q is not absolute", u)
+	}
+	if !strings.HasSuffix(u, "/") {
+		u += "/"
+	}
+	return u, nil
+}
+
+// WithUserAgent configures the Client to send the provided User-Agent header with each request.
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) error {
+		c.userAgent = ua
+		return nil
+	}
+}
+
+// WithChannel configures the Client to send the provided channel with each request.
+//
+// Channels are used by Google Maps API for Work clients to track usage across applications.
+func WithChannel(channel string) ClientOption {
+	return func(c *Client) error {
+		c.channel = channel
+		return nil
+	}
+}
+
+// setWorkCredentials sets the Client's Google Maps API for Work credentials, decoding the private key
+// so that it need not be decoded for each request. If the key is malformed, the error is reported by workKeyErr.
+func (c *Client) setWorkCredentials(clientID, privateKey string) {
+	c.clientID = clientID
+	c.workKey, c.workKeyErr = nil, nil
+	if privateKey != "" {
+		c.workKey, c.workKeyErr = decodeKey(privateKey)
+		if c.workKeyErr != nil {
+			c.workKeyErr = fmt.Errorf("invalid private key: %v", c.workKeyErr)
+		}
+	}
+}
+
+func (c *Client) clone() *Client {
+	cc := *c
+	cc.serviceURL = make(map[Service]string, len(c.serviceURL))
+	for k, v := range c.serviceURL {
+		cc.serviceURL[k] = v
+	}
+	return &cc
+}
+
+// serviceBaseURL returns the base URL to which requests for the given Service are sent.
+func (c *Client) serviceBaseURL(svc Service) string {
+	if u, ok := c.serviceURL[svc]; ok {
+		return u
+	}
+	if svc == ServiceRoads {
+		return roadsAPIBaseURL
+	}
+	return c.baseURL
+}
+
+func (c *Client) retryPolicy() RetryPolicy {
+	if c.retry != nil {
+		return c.retry
+	}
+	return ExponentialBackoff{}
+}
+
+func (c *Client) client() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	return &http.Client{Transport: &RetryTransport{Policy: c.retryPolicy()}}
+}
@@ -7,17 +7,21 @@ import (
 
 type contextKey int
 
+var defaultClient = &Client{baseURL: baseURL}
+
 // NewContext returns a new context that uses the provided http.Client and API key.
 func NewContext(key string, c *http.Client) context.Context {
 	return WithContext(context.Background(), key, c)
 }
 
 // WithContext returns a new context in a similar way NewContext does, but initiates the new context with the specified parent.
+//
+// Any other configuration of the parent context, such as work credentials, is preserved.
 func WithContext(parent context.Context, key string, c *http.Client) context.Context {
-	vals := map[string]interface{}{}
-	vals["key"] = key
-	vals["httpClient"] = c
-	return context.WithValue(parent, contextKey(0), vals)
+	cl := fromContext(parent).clone()
+	cl.key = key
+	cl.httpClient = c
+	return WithClient(parent, cl)
 }
 
 // NewWorkContext returns a new context that uses the provided http.Client and Google Maps API for Work client ID and private key.
@@ -26,41 +30,25 @@ func NewWorkContext(clientID, privateKey string, c *http.Client) context.Context
 }
 
 // WithWorkCredentials returns a new context in a similar way NewWorkContext does, but initiates the new context with the specified parent.
+//
+// Any other configuration of the parent context, such as an API key, is preserved.
 func WithWorkCredentials(parent context.Context, clientID, privateKey string, c *http.Client) context.Context {
-	vals := map[string]interface{}{}
-	vals["workClientID"] = clientID
-	vals["workPrivKey"] = privateKey
-	vals["httpClient"] = c
-	return context.WithValue(parent, contextKey(0), vals)
-}
-
-func key(ctx context.Context) string {
-	k, found := ctx.Value(contextKey(0)).(map[string]interface{})["key"]
-	if found {
-		return k.(string)
-	}
-	return ""
+	cl := fromContext(parent).clone()
+	// If the private key is malformed, the error is returned by each request.
+	cl.setWorkCredentials(clientID, privateKey)
+	cl.httpClient = c
+	return WithClient(parent, cl)
 }
 
-func workCreds(ctx context.Context) (string, string) {
-	var clientID string
-	cid, found := ctx.Value(contextKey(0)).(map[string]interface{})["workClientID"]
-	if found {
-		clientID = cid.(string)
-	}
-
-	var privateKey string
-	pkey, found := ctx.Value(contextKey(0)).(map[string]interface{})["workPrivKey"]
-	if found {
-		privateKey = pkey.(string)
-	}
-	return clientID, privateKey
+// WithClient returns a new context that uses the provided Client for package-level functions such as Directions and Geocode.
+func WithClient(parent context.Context, c *Client) context.Context {
+	return context.WithValue(parent, contextKey(0), c)
 }
 
-func httpClient(ctx context.Context) *http.Client {
-	cl, found := ctx.Value(contextKey(0)).(map[string]interface{})["httpClient"]
-	if found && cl != nil {
-		return cl.(*http.Client)
+// fromContext returns the Client configured in ctx, or a default Client if none is configured.
+func fromContext(ctx context.Context) *Client {
+	if c, ok := ctx.Value(contextKey(0)).(*Client); ok && c != nil {
+		return c
 	}
-	return &http.Client{Transport: &backoff{Transport: http.DefaultTransport}}
+	return defaultClient
 }
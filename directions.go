@@ -2,8 +2,11 @@ package maps
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"image/color"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -33,17 +36,21 @@ const (
 
 // TODO: via_waypoint not documented
 
-// Directions requests routes between orig and dest Locations.
+// Directions requests routes between orig and dest Locations, using the Client configured in ctx.
 //
 // See https://developers.google.com/maps/documentation/directions/
 func Directions(ctx context.Context, orig, dest Location, opts *DirectionsOpts) ([]Route, error) {
+	return fromContext(ctx).Directions(ctx, orig, dest, opts)
+}
+
+// Directions requests routes between orig and dest Locations.
+//
+// See https://developers.google.com/maps/documentation/directions/
+func (c *Client) Directions(ctx context.Context, orig, dest Location, opts *DirectionsOpts) ([]Route, error) {
 	var d directionsResponse
-	if err := doDecode(ctx, baseURL+directions(orig, dest, opts), &d); err != nil {
+	if err := c.doDecode(ctx, ServiceDirections, directions(orig, dest, opts), &d); err != nil {
 		return nil, err
 	}
-	if d.Status != StatusOK {
-		return nil, APIError{d.Status, d.ErrorMessage}
-	}
 	return d.Routes, nil
 }
 
@@ -155,6 +162,8 @@ type directionsResponse struct {
 	Routes       []Route `json:"routes"`
 }
 
+func (r directionsResponse) apiStatus() (string, string) { return r.Status, r.ErrorMessage }
+
 // Route describes a possible route between the requested origin and destination.
 type Route struct {
 	// Summary contains a short textual description of the route, suitable for naming and disambiguating the route from alternatives.
@@ -165,51 +174,7 @@ type Route struct {
 	// requested, this will contain one element.
 	//
 	// See https://developers.google.com/maps/documentation/directions/#Legs
-	Legs []struct {
-		// Duration indicates the total duration of this leg.
-		Duration *Duration `json:"duration"`
-
-		// DurationInTraffic indicates the total duration of this leg, taking into account current traffic conditions.
-		//
-		// It will only be included if all of the following are true:
-		// - The directions request includes a DepartureTime parameter set to a value within a few minutes of the current time.
-		// - The request is made using a Google Maps API for Work client.
-		// - Traffic conditions are available for the requested route.
-		// - The directions request doesnot include stopover waypoints.
-		DurationInTraffic *Duration `json:"duration_in_traffic"`
-
-		// Distance is the total distance covered by this leg.
-		Distance *Distance `json:"distance"`
-
-		// ArrivalTime indicates the estimated time of arrival for this leg. This is only included for transit directions.
-		ArrivalTime Time `json:"arrival_time"`
-
-		// DepartureTime indicates the estimated time of arrival for this leg. This is only included for transit directions.
-		DepartureTime Time `json:"departure_time"`
-
-		// StartLocation indicates the origin of this leg.
-		//
-		// Because the Directions API calculates directions between locations by using the nearest transportation option (usually a road)
-		// at the start and end points, StartLocation may be different than the provided origin of this leg if, for example, a road is not near the origin.
-		StartLocation *LatLng `json:"start_location"`
-
-		// EndLocation indicates the destination of this leg.
-		//
-		// Because the Directions API calculates directions between locations by using the nearest transportation option (usually a road)
-		// at the start and end points, EndLocation may be different than the provided destination of this leg if, for example, a road is not near the destination.
-		EndLocation *LatLng `json:"end_location"`
-
-		// StartAddress contains the human-readable address (typically a street address) reflecting the StartLocation of this leg.
-		StartAddress string `json:"start_address"`
-
-		// EndAddress contains the human-readable address (typically a street address) reflecting the EndLocation of this leg.
-		EndAddress string `json:"end_address"`
-
-		// Steps describes each step of the leg of the journey.
-		//
-		// See https://developers.google.com/maps/documentation/directions/#Steps
-		Steps []Step `json:"steps"`
-	} `json:"legs"`
+	Legs []Leg `json:"legs"`
 
 	// Bounds describes the viewport bounding box of the OverviewPolyline
 	Bounds Bounds `json:"bounds"`
@@ -227,6 +192,134 @@ type Route struct {
 	WaypointOrder []int `json:"waypoint_order"`
 }
 
+// Distance returns the total distance covered by the route's legs, in meters.
+func (r Route) Distance() int64 {
+	var d int64
+	for _, l := range r.Legs {
+		if l.Distance != nil {
+			d += l.Distance.Value
+		}
+	}
+	return d
+}
+
+// Duration returns the total duration of the route's legs.
+func (r Route) Duration() time.Duration {
+	var d time.Duration
+	for _, l := range r.Legs {
+		if l.Duration != nil {
+			d += l.Duration.Duration()
+		}
+	}
+	return d
+}
+
+// DurationInTraffic returns the total duration of the route's legs, taking into account current traffic conditions.
+//
+// Legs without a DurationInTraffic contribute their Duration. If no legs include a DurationInTraffic, ok is false.
+func (r Route) DurationInTraffic() (d time.Duration, ok bool) {
+	for _, l := range r.Legs {
+		if l.DurationInTraffic != nil {
+			d += l.DurationInTraffic.Duration()
+			ok = true
+		} else if l.Duration != nil {
+			d += l.Duration.Duration()
+		}
+	}
+	return d, ok
+}
+
+// Steps returns the steps of each of the route's legs, in order.
+//
+// Steps which contain detailed sub-steps, such as walking steps in transit directions, are replaced by their sub-steps.
+func (r Route) Steps() []Step {
+	var ss []Step
+	var add func([]Step)
+	add = func(steps []Step) {
+		for _, s := range steps {
+			if len(s.Steps) > 0 {
+				add(s.Steps)
+			} else {
+				ss = append(ss, s)
+			}
+		}
+	}
+	for _, l := range r.Legs {
+		add(l.Steps)
+	}
+	return ss
+}
+
+// FullPath returns the full-resolution path of the route, by decoding and concatenating the polyline of each of its Steps.
+//
+// Unlike the OverviewPolyline, the path is not smoothed. Where one step begins at the location where the previous step ended, the location is only included once.
+func (r Route) FullPath() ([]LatLng, error) {
+	var path []LatLng
+	for _, s := range r.Steps() {
+		if s.Polyline == nil {
+			continue
+		}
+		ll, err := s.Polyline.Decode()
+		if err != nil {
+			return nil, err
+		}
+		if len(path) > 0 && len(ll) > 0 && path[len(path)-1] == ll[0] {
+			ll = ll[1:]
+		}
+		path = append(path, ll...)
+	}
+	return path, nil
+}
+
+// Leg describes a leg of a route, between two locations within the route.
+//
+// See https://developers.google.com/maps/documentation/directions/#Legs
+type Leg struct {
+	// Duration indicates the total duration of this leg.
+	Duration *Duration `json:"duration"`
+
+	// DurationInTraffic indicates the total duration of this leg, taking into account current traffic conditions.
+	//
+	// It will only be included if all of the following are true:
+	// - The directions request includes a DepartureTime parameter set to a value within a few minutes of the current time.
+	// - The request is made using a Google Maps API for Work client.
+	// - Traffic conditions are available for the requested route.
+	// - The directions request doesnot include stopover waypoints.
+	DurationInTraffic *Duration `json:"duration_in_traffic"`
+
+	// Distance is the total distance covered by this leg.
+	Distance *Distance `json:"distance"`
+
+	// ArrivalTime indicates the estimated time of arrival for this leg. This is only included for transit directions.
+	ArrivalTime Time `json:"arrival_time"`
+
+	// DepartureTime indicates the estimated time of arrival for this leg. This is only included for transit directions.
+	DepartureTime Time `json:"departure_time"`
+
+	// StartLocation indicates the origin of this leg.
+	//
+	// Because the Directions API calculates directions between locations by using the nearest transportation option (usually a road)
+	// at the start and end points, StartLocation may be different than the provided origin of this leg if, for example, a road is not near the origin.
+	StartLocation *LatLng `json:"start_location"`
+
+	// EndLocation indicates the destination of this leg.
+	//
+	// Because the Directions API calculates directions between locations by using the nearest transportation option (usually a road)
+	// at the start and end points, EndLocation may be different than the provided destination of this leg if, for example, a road is not near the destination.
+	EndLocation *LatLng `json:"end_location"`
+
+	// StartAddress contains the human-readable address (typically a street address) reflecting the StartLocation of this leg.
+	StartAddress string `json:"start_address"`
+
+	// EndAddress contains the human-readable address (typically a street address) reflecting the EndLocation of this leg.
+	EndAddress string `json:"end_address"`
+
+	// Steps describes each step of the leg of the journey.
+	//
+	// See https://developers.google.com/maps/documentation/directions/#Steps
+	Steps []Step `json:"steps"`
+}
+
 // Time represents a time.
 type Time struct {
 	// Value indicates the number of seconds since epoch of this time.
@@ -281,81 +374,169 @@ type Step struct {
 	// TransitDetails contains transit-specific information. This is only included when the requested Mode is ModeTransit
 	//
 	// See https://developers.google.com/maps/documentation/directions/#TransitDetails
-	TransitDetails *struct {
-		// ArrivalStop contains information about the arrival stop for this part of the trip.
-		ArrivalStop Stop `json:"arrival_stop"`
+	TransitDetails *TransitDetails `json:"transit_details"`
+}
 
-		// DepartureStop contains information about the departure stop for this part of the trip.
-		DepartureStop Stop `json:"departure_stop"`
+// TransitDetails contains transit-specific information about a step.
+//
+// See https://developers.google.com/maps/documentation/directions/#TransitDetails
+type TransitDetails struct {
+	// ArrivalStop contains information about the arrival stop for this part of the trip.
+	ArrivalStop Stop `json:"arrival_stop"`
 
-		// ArrivalTime indicates the arrival time for this leg of the journey.
-		ArrivalTime Time `json:"arrival_time"`
+	// DepartureStop contains information about the departure stop for this part of the trip.
+	DepartureStop Stop `json:"departure_stop"`
 
-		// DepartureTime indicates the departure time for this leg of the journey.
-		DepartureTime Time `json:"departure_time"`
+	// ArrivalTime indicates the arrival time for this leg of the journey.
+	ArrivalTime Time `json:"arrival_time"`
 
-		// Headsign specifies the direction in which to travel on this line, as it is marked on the vehicle or at the departure stop. This will often be the terminus station.
-		Headsign string `json:"headsign"`
+	// DepartureTime indicates the departure time for this leg of the journey.
+	DepartureTime Time `json:"departure_time"`
 
-		// Headway specifies the expected number of seconds between departure from the same stop at this time.
-		//
-		// For example, with a Headway of 600, you would expect a ten minute way if you should miss your bus.
-		Headway int64 `json:"headway"` // TODO: to time.Duration?
+	// Headsign specifies the direction in which to travel on this line, as it is marked on the vehicle or at the departure stop. This will often be the terminus station.
+	Headsign string `json:"headsign"`
 
-		// NumStops indicates the number of stops in this step, counting the arrival stop, but not the departure stop.
-		//
-		// For example, if your directions involve leaving Stop A, passing through Stops B and C, and arriving at Stop D, NumStops will be 3.
-		NumStops int `json:"num_stops"`
+	// Headway specifies the expected time between departures from the same stop at this time.
+	//
+	// For example, with a Headway of ten minutes, you would expect a ten minute wait if you should miss your bus.
+	Headway time.Duration `json:"-"`
 
-		// Line contains information about the transit line used in this step.
-		Line struct {
-			// Name is the full name of this transit line, e.g., "7 Avenue Express"
-			Name string `json:"name"`
+	// NumStops indicates the number of stops in this step, counting the arrival stop, but not the departure stop.
+	//
+	// For example, if your directions involve leaving Stop A, passing through Stops B and C, and arriving at Stop D, NumStops will be 3.
+	NumStops int `json:"num_stops"`
 
-			// ShortName is the short name of this transit line. This will normally be a line number, such as "M7" or "355"
-			ShortName string `json:"short_name"`
+	// Line contains information about the transit line used in this step.
+	Line TransitLine `json:"line"`
+}
 
-			// Color is the color commonly used in signage for this transit line, specified as a hex string such as "#FF0033"
-			Color string `json:"color"` // TODO: to image.Color?
+// transitDetails is TransitDetails without its JSON methods, to avoid recursion when marshaling and unmarshaling.
+type transitDetails TransitDetails
+
+// UnmarshalJSON implements json.Unmarshaler, parsing the Headway from a number of seconds.
+func (t *TransitDetails) UnmarshalJSON(b []byte) error {
+	v := struct {
+		*transitDetails
+		Headway int64 `json:"headway"`
+	}{transitDetails: (*transitDetails)(t)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	t.Headway = time.Duration(v.Headway) * time.Second
+	return nil
+}
 
-			// Agencies contains information about the operator of the line.
-			//
-			// You must display the names and URLs of the transit agencies servicing the trip results.
-			Agencies []struct {
-				// Name is the name of the transit agency.
-				Name string `json:"name"`
+// MarshalJSON implements json.Marshaler, encoding the Headway as a number of seconds.
+func (t TransitDetails) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		transitDetails
+		Headway int64 `json:"headway"`
+	}{transitDetails(t), int64(t.Headway / time.Second)})
+}
 
-				// URL is the URL for the transit agency.
-				URL string `json:"url"`
+// TransitLine describes a transit line.
+type TransitLine struct {
+	// Name is the full name of this transit line, e.g., "7 Avenue Express"
+	Name string `json:"name"`
 
-				// Phone is the phone number of the transit agency.
-				Phone string `json:"phone"`
-			} `json:"agencies"`
+	// ShortName is the short name of this transit line. This will normally be a line number, such as "M7" or "355"
+	ShortName string `json:"short_name"`
 
-			// URL is the URL for this transit line as provided by the transit agency.
-			URL string `json:"url"`
+	// Color is the color commonly used in signage for this transit line, or nil if none is specified or it cannot be parsed.
+	Color color.Color `json:"-"`
 
-			// IconURL is the URL for the icon associated with this line.
-			IconURL string `json:"icon"`
+	// Agencies contains information about the operator of the line.
+	//
+	// You must display the names and URLs of the transit agencies servicing the trip results.
+	Agencies []TransitAgency `json:"agencies"`
 
-			// TextColor is the color of text commonly used for signage of this line, specified as a hex string such as "#FF0033"
-			TextColor string `json:"text_color"` // TODO: to image.Color?
+	// URL is the URL for this transit line as provided by the transit agency.
+	URL string `json:"url"`
 
-			// Vehicle contains the type of vehicle used on this line.
-			Vehicle *struct {
-				// Name is the name of the vehicle used on this line, e.g., "Subway"
-				Name string `json:"name"`
+	// IconURL is the URL for the icon associated with this line.
+	IconURL string `json:"icon"`
 
-				// Type is the type of the vehicle that runs on this line.
-				//
-				// See https://developers.google.com/maps/documentation/directions/#VehicleType
-				Type string `json:"type"`
+	// TextColor is the color of text commonly used for signage of this line, or nil if none is specified or it cannot be parsed.
+	TextColor color.Color `json:"-"`
 
-				// IconURL is the URL for an icon associated with this vehicle type.
-				IconURL string `json:"icon"`
-			} `json:"vehicle"`
-		} `json:"line"`
-	} `json:"transit_details"`
+	// Vehicle contains the type of vehicle used on this line.
+	Vehicle *Vehicle `json:"vehicle"`
+}
+
+// transitLine is TransitLine without its JSON methods, to avoid recursion when marshaling and unmarshaling.
+type transitLine TransitLine
+
+// UnmarshalJSON implements json.Unmarshaler, parsing the Color and TextColor from hex strings such as "#FF0033".
+//
+// Colors which cannot be parsed are left nil, rather than failing to decode the response.
+func (l *TransitLine) UnmarshalJSON(b []byte) error {
+	v := struct {
+		*transitLine
+		Color     string `json:"color"`
+		TextColor string `json:"text_color"`
+	}{transitLine: (*transitLine)(l)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	l.Color, _ = parseHexColor(v.Color)
+	l.TextColor, _ = parseHexColor(v.TextColor)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the Color and TextColor as hex strings such as "#FF0033".
+func (l TransitLine) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		transitLine
+		Color     string `json:"color"`
+		TextColor string `json:"text_color"`
+	}{transitLine(l), hexColor(l.Color), hexColor(l.TextColor)})
+}
+
+// parseHexColor parses a color specified as a hex string such as "#FF0033". An empty string is parsed as a nil color.
+func parseHexColor(s string) (color.Color, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	if err != nil || len(s) != 7 || s[0] != '#' {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xFF}, nil
+}
+
+// hexColor formats the color as a hex string such as "#FF0033". A nil color is formatted as an empty string.
+func hexColor(c color.Color) string {
+	if c == nil {
+		return ""
+	}
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02X%02X%02X", r>>8, g>>8, b>>8)
+}
+
+// TransitAgency describes the operator of a transit line.
+type TransitAgency struct {
+	// Name is the name of the transit agency.
+	Name string `json:"name"`
+
+	// URL is the URL for the transit agency.
+	URL string `json:"url"`
+
+	// Phone is the phone number of the transit agency.
+	Phone string `json:"phone"`
+}
+
+// Vehicle describes the type of vehicle used on a transit line.
+type Vehicle struct {
+	// Name is the name of the vehicle used on this line, e.g., "Subway"
+	Name string `json:"name"`
+
+	// Type is the type of the vehicle that runs on this line.
+	//
+	// See https://developers.google.com/maps/documentation/directions/#VehicleType
+	Type string `json:"type"`
+
+	// IconURL is the URL for an icon associated with this vehicle type.
+	IconURL string `json:"icon"`
 }
 
 // Stop describes a transit station or stop.
@@ -368,6 +549,8 @@ type Stop struct {
 }
 
 // Bounds describes a viewport bounding box.
+//
+// The zero Bounds contains only the location 0,0. Use NewBounds with no locations for an empty bounding box; see IsEmpty.
 type Bounds struct {
 	// The northeasternmost point of the bounding box.
 	Northeast LatLng `json:"northeast"`
@@ -410,5 +593,3 @@ type Polyline struct {
 	// See https://developers.google.com/maps/documentation/utilities/polylinealgorithm
 	Points string `json:"points"`
 }
-
-// TODO: methods to decode polyline points?
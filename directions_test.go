@@ -0,0 +1,161 @@
+package maps
+
+import (
+	"encoding/json"
+	"image/color"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTransitDetailsJSON(t *testing.T) {
+	const in = `{
+		"headsign": "Downtown",
+		"headway": 600,
+		"num_stops": 3,
+		"line": {
+			"name": "7 Avenue Express",
+			"short_name": "2",
+			"color": "#ee352e",
+			"text_color": "#FFFFFF",
+			"agencies": [{"name": "MTA New York City Transit", "url": "http://www.mta.info/"}],
+			"vehicle": {"name": "Subway", "type": "SUBWAY"}
+		}
+	}`
+	var td TransitDetails
+	if err := json.Unmarshal([]byte(in), &td); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if td.Headway != 10*time.Minute {
+		t.Errorf("got headway %v, want 10m", td.Headway)
+	}
+	if td.Headsign != "Downtown" || td.NumStops != 3 {
+		t.Errorf("got %+v", td)
+	}
+	if exp := (color.RGBA{0xEE, 0x35, 0x2E, 0xFF}); td.Line.Color != exp {
+		t.Errorf("got color %v, want %v", td.Line.Color, exp)
+	}
+	if exp := (color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}); td.Line.TextColor != exp {
+		t.Errorf("got text color %v, want %v", td.Line.TextColor, exp)
+	}
+	if td.Line.Vehicle == nil || td.Line.Vehicle.Type != "SUBWAY" || len(td.Line.Agencies) != 1 {
+		t.Errorf("got line %+v", td.Line)
+	}
+
+	// Marshaling produces the same representation.
+	b, err := json.Marshal(td)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var got, want map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(in), &want); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"headway", "headsign", "num_stops"} {
+		if !reflect.DeepEqual(got[k], want[k]) {
+			t.Errorf("%s: got %v, want %v", k, got[k], want[k])
+		}
+	}
+	line := got["line"].(map[string]interface{})
+	if line["color"] != "#EE352E" || line["text_color"] != "#FFFFFF" || line["short_name"] != "2" {
+		t.Errorf("got line %v", line)
+	}
+
+	// Missing and invalid colors are nil, and don't prevent the rest of the line from being decoded.
+	for _, in := range []string{
+		`{"name": "M7"}`,
+		`{"name": "M7", "color": "red", "text_color": "#FFF"}`,
+		`{"name": "M7", "color": "FF0033", "text_color": "#GGGGGG"}`,
+	} {
+		var l TransitLine
+		if err := json.Unmarshal([]byte(in), &l); err != nil || l.Name != "M7" || l.Color != nil || l.TextColor != nil {
+			t.Errorf("%s: got %+v, %v; want nil colors", in, l, err)
+		}
+	}
+}
+
+func TestLeg(t *testing.T) {
+	// Legs and their steps can be constructed directly.
+	r := Route{Legs: []Leg{{
+		StartAddress: "Home",
+		Steps: []Step{{
+			TransitDetails: &TransitDetails{Line: TransitLine{ShortName: "M7", Color: color.Black}},
+		}},
+	}}}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var got Route
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if td := got.Legs[0].Steps[0].TransitDetails; td == nil || td.Line.ShortName != "M7" || hexColor(td.Line.Color) != "#000000" {
+		t.Errorf("got %+v", got.Legs[0])
+	}
+}
+
+func TestRouteHelpers(t *testing.T) {
+	const in = `{
+		"legs": [{
+			"distance": {"value": 1000},
+			"duration": {"value": 60},
+			"duration_in_traffic": {"value": 90},
+			"steps": [{
+				"html_instructions": "Walk to Station",
+				"polyline": {"points": "_p~iF~ps|U_ulLnnqC"},
+				"steps": [
+					{"html_instructions": "Head north", "polyline": {"points": "_p~iF~ps|U"}},
+					{"html_instructions": "Turn left", "polyline": {"points": "_p~iF~ps|U_ulLnnqC"}}
+				]
+			}]
+		}, {
+			"distance": {"value": 500},
+			"duration": {"value": 30},
+			"steps": [
+				{"html_instructions": "Take the train", "polyline": {"points": "_flwFn` + "`" + `faVwrq@` + "`" + `qoA"}}
+			]
+		}]
+	}`
+	var r Route
+	if err := json.Unmarshal([]byte(in), &r); err != nil {
+		t.Fatal(err)
+	}
+	if d := r.Distance(); d != 1500 {
+		t.Errorf("got distance %d, want 1500", d)
+	}
+	if d := r.Duration(); d != 90*time.Second {
+		t.Errorf("got duration %v, want 1m30s", d)
+	}
+	if d, ok := r.DurationInTraffic(); !ok || d != 2*time.Minute {
+		t.Errorf("got duration in traffic %v, %t; want 2m, true", d, ok)
+	}
+	if _, ok := (Route{Legs: r.Legs[1:]}).DurationInTraffic(); ok {
+		t.Error("got duration in traffic for leg without traffic")
+	}
+
+	var instructions []string
+	for _, s := range r.Steps() {
+		instructions = append(instructions, s.HTMLInstructions)
+	}
+	if exp := []string{"Head north", "Turn left", "Take the train"}; !reflect.DeepEqual(instructions, exp) {
+		t.Errorf("got steps %q, want %q", instructions, exp)
+	}
+
+	path, err := r.FullPath()
+	if err != nil {
+		t.Fatalf("FullPath: %v", err)
+	}
+	// Each joint between steps appears only once.
+	if exp := []LatLng{{38.5, -120.2}, {40.7, -120.95}, {40.95916, -121.36249}}; !reflect.DeepEqual(path, exp) {
+		t.Errorf("got path %v, want %v", path, exp)
+	}
+
+	r.Legs[1].Steps[0].Polyline.Points = "_p~iF~ps|U_ulLnnqC_"
+	if _, err := r.FullPath(); err == nil {
+		t.Error("expected error decoding invalid polyline")
+	}
+}
@@ -7,17 +7,21 @@ import (
 	"time"
 )
 
-// DistanceMatrix requests travel distance and time for a matrix of origins and destinations.
+// DistanceMatrix requests travel distance and time for a matrix of origins and destinations, using the Client configured in ctx.
 //
 // See https://developers.google.com/maps/documentation/distancematrix/
 func DistanceMatrix(ctx context.Context, orig, dest []Location, opts *DistanceMatrixOpts) (*DistanceMatrixResult, error) {
+	return fromContext(ctx).DistanceMatrix(ctx, orig, dest, opts)
+}
+
+// DistanceMatrix requests travel distance and time for a matrix of origins and destinations.
+//
+// See https://developers.google.com/maps/documentation/distancematrix/
+func (c *Client) DistanceMatrix(ctx context.Context, orig, dest []Location, opts *DistanceMatrixOpts) (*DistanceMatrixResult, error) {
 	var d distanceResponse
-	if err := doDecode(ctx, baseURL+distancematrix(orig, dest, opts), &d); err != nil {
+	if err := c.doDecode(ctx, ServiceDistanceMatrix, distancematrix(orig, dest, opts), &d); err != nil {
 		return nil, err
 	}
-	if d.Status != StatusOK {
-		return nil, APIError{d.Status, ""}
-	}
 	return &d.DistanceMatrixResult, nil
 }
 
@@ -83,10 +87,13 @@ func (o *DistanceMatrixOpts) update(p url.Values) {
 }
 
 type distanceResponse struct {
-	Status string `json:"status"`
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
 	DistanceMatrixResult
 }
 
+func (r distanceResponse) apiStatus() (string, string) { return r.Status, r.ErrorMessage }
+
 // DistanceMatrixResult describes the matrix of distances between the requested origins and destinations.
 type DistanceMatrixResult struct {
 	// OriginAddresses contains the addresses returned by the API from your original request.
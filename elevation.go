@@ -6,61 +6,66 @@ import (
 	"net/url"
 )
 
-// Elevation requests elevation data for a series of locations.
+// Elevation requests elevation data for a series of locations, using the Client configured in ctx.
 //
 // See https://developers.google.com/maps/documentation/elevation/
 func Elevation(ctx context.Context, ll []LatLng) ([]ElevationResult, error) {
-	var r elevationResponse
-	if err := doDecode(ctx, baseURL+elevation(ll), &r); err != nil {
-		return nil, err
-	}
-	if r.Status != StatusOK {
-		return nil, APIError{r.Status, ""}
-	}
-	return r.Results, nil
+	return fromContext(ctx).Elevation(ctx, ll)
 }
 
-// ElevationPolyline requests elevation data for a series of locations as specified as an encoded polyline.
+// Elevation requests elevation data for a series of locations.
+//
+// See https://developers.google.com/maps/documentation/elevation/
+func (c *Client) Elevation(ctx context.Context, ll []LatLng) ([]ElevationResult, error) {
+	return c.getElevation(ctx, elevation(ll))
+}
+
+// ElevationPolyline requests elevation data for a series of locations as specified as an encoded polyline, using the Client configured in ctx.
 //
 // See https://developers.google.com/maps/documentation/elevation/#Locations
 func ElevationPolyline(ctx context.Context, p string) ([]ElevationResult, error) {
-	var r elevationResponse
-	if err := doDecode(ctx, baseURL+elevationpoly(p), &r); err != nil {
-		return nil, err
-	}
-	if r.Status != StatusOK {
-		return nil, APIError{r.Status, ""}
-	}
-	return r.Results, nil
+	return fromContext(ctx).ElevationPolyline(ctx, p)
 }
 
-// ElevationPath requests elevation data for a number of samples along a path described as a series of locations.
+// ElevationPolyline requests elevation data for a series of locations as specified as an encoded polyline.
+//
+// See https://developers.google.com/maps/documentation/elevation/#Locations
+func (c *Client) ElevationPolyline(ctx context.Context, p string) ([]ElevationResult, error) {
+	return c.getElevation(ctx, elevationpoly(p))
+}
+
+// ElevationPath requests elevation data for a number of samples along a path described as a series of locations, using the Client configured in ctx.
 func ElevationPath(ctx context.Context, ll []LatLng, samples int) ([]ElevationResult, error) {
-	var r elevationResponse
-	if err := doDecode(ctx, baseURL+elevationpath(ll, samples), &r); err != nil {
-		return nil, err
-	}
-	if r.Status != StatusOK {
-		return nil, APIError{r.Status, ""}
-	}
-	return r.Results, nil
+	return fromContext(ctx).ElevationPath(ctx, ll, samples)
 }
 
-// ElevationPathPoly requests elevation data for a number of samples along a path described as a series of locations specified as an encoded polyline.
+// ElevationPath requests elevation data for a number of samples along a path described as a series of locations.
+func (c *Client) ElevationPath(ctx context.Context, ll []LatLng, samples int) ([]ElevationResult, error) {
+	return c.getElevation(ctx, elevationpath(ll, samples))
+}
+
+// ElevationPathPoly requests elevation data for a number of samples along a path described as a series of locations specified as an encoded polyline,
+// using the Client configured in ctx.
 func ElevationPathPoly(ctx context.Context, p string, samples int) ([]ElevationResult, error) {
+	return fromContext(ctx).ElevationPathPoly(ctx, p, samples)
+}
+
+// ElevationPathPoly requests elevation data for a number of samples along a path described as a series of locations specified as an encoded polyline.
+func (c *Client) ElevationPathPoly(ctx context.Context, p string, samples int) ([]ElevationResult, error) {
+	return c.getElevation(ctx, elevationpathpoly(p, samples))
+}
+
+func (c *Client) getElevation(ctx context.Context, path string) ([]ElevationResult, error) {
 	var r elevationResponse
-	if err := doDecode(ctx, baseURL+elevationpathpoly(p, samples), &r); err != nil {
+	if err := c.doDecode(ctx, ServiceElevation, path, &r); err != nil {
 		return nil, err
 	}
-	if r.Status != StatusOK {
-		return nil, APIError{r.Status, ""}
-	}
 	return r.Results, nil
 }
 
 func elevation(ll []LatLng) string {
 	p := url.Values{}
-	p.Set("locations", encodeLatLngs(ll))
+	p.Set("locations", encodeLatLngPath(ll))
 	return "elevation/json?" + p.Encode()
 }
 
@@ -72,7 +77,7 @@ func elevationpoly(poly string) string {
 
 func elevationpath(ll []LatLng, s int) string {
 	p := url.Values{}
-	p.Set("path", encodeLatLngs(ll))
+	p.Set("path", encodeLatLngPath(ll))
 	p.Set("samples", fmt.Sprintf("%d", s))
 	return "elevation/json?" + p.Encode()
 }
@@ -85,10 +90,13 @@ func elevationpathpoly(poly string, s int) string {
 }
 
 type elevationResponse struct {
-	Results []ElevationResult `json:"results"`
-	Status  string            `json:"status"`
+	Results      []ElevationResult `json:"results"`
+	Status       string            `json:"status"`
+	ErrorMessage string            `json:"error_message"`
 }
 
+func (r elevationResponse) apiStatus() (string, string) { return r.Status, r.ErrorMessage }
+
 // ElevationResult describes elevation data.
 type ElevationResult struct {
 	// Elevation is elevation of the location in meters.
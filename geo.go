@@ -0,0 +1,96 @@
+package maps
+
+import "math"
+
+// EarthRadius is the mean radius of the Earth in meters, used for geodesic calculations which model the Earth as a sphere.
+const EarthRadius = 6371008.8
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
+
+// Distance returns the great-circle distance in meters between two locations, using the haversine formula.
+func (ll LatLng) Distance(o LatLng) float64 {
+	return EarthRadius * ll.angle(o)
+}
+
+// angle returns the central angle in radians between two locations.
+func (ll LatLng) angle(o LatLng) float64 {
+	lat1, lat2 := radians(ll.Lat), radians(o.Lat)
+	dLat, dLng := lat2-lat1, radians(o.Lng-ll.Lng)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	// Rounding can push a slightly outside [0, 1] for near-antipodal points.
+	a = math.Min(1, math.Max(0, a))
+	return 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// Bearing returns the initial bearing in degrees, clockwise from north between 0 and 360, of the great-circle path from ll to o.
+func (ll LatLng) Bearing(o LatLng) float64 {
+	lat1, lat2 := radians(ll.Lat), radians(o.Lat)
+	dLng := radians(o.Lng - ll.Lng)
+	y := math.Sin(dLng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLng)
+	return math.Mod(degrees(math.Atan2(y, x))+360, 360)
+}
+
+// FinalBearing returns the bearing in degrees, clockwise from north between 0 and 360, at which the great-circle path from ll arrives at o.
+//
+// The bearing along a great-circle path varies unless it follows a meridian or the equator.
+func (ll LatLng) FinalBearing(o LatLng) float64 {
+	return math.Mod(o.Bearing(ll)+180, 360)
+}
+
+// Destination returns the location reached by traveling the given distance in meters from ll along a great-circle path
+// with the given initial bearing in degrees clockwise from north.
+func (ll LatLng) Destination(distance, bearing float64) LatLng {
+	lat1, lng1 := radians(ll.Lat), radians(ll.Lng)
+	brng, ang := radians(bearing), distance/EarthRadius
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(ang) + math.Cos(lat1)*math.Sin(ang)*math.Cos(brng))
+	lng2 := lng1 + math.Atan2(math.Sin(brng)*math.Sin(ang)*math.Cos(lat1), math.Cos(ang)-math.Sin(lat1)*math.Sin(lat2))
+	return LatLng{degrees(lat2), normalizeLng(degrees(lng2))}
+}
+
+// Midpoint returns the location halfway along the great-circle path between two locations.
+//
+// As for Interpolate, the path between antipodal locations is taken to head north from ll.
+func (ll LatLng) Midpoint(o LatLng) LatLng {
+	return ll.Interpolate(o, 0.5)
+}
+
+// Interpolate returns the location at the given fraction of the way along the great-circle path from ll to o,
+// where 0 returns ll and 1 returns o.
+//
+// Antipodal locations are joined by every great circle through them, so for those, the path heading north from ll
+// (or heading away from ll along its meridian, if ll is a pole) is used.
+func (ll LatLng) Interpolate(o LatLng, f float64) LatLng {
+	ang := ll.angle(o)
+	if ang == 0 {
+		return ll
+	}
+	if math.Sin(ang) < 1e-9 {
+		return ll.Destination(f*ang*EarthRadius, 0)
+	}
+	lat1, lng1 := radians(ll.Lat), radians(ll.Lng)
+	lat2, lng2 := radians(o.Lat), radians(o.Lng)
+	a, b := math.Sin((1-f)*ang)/math.Sin(ang), math.Sin(f*ang)/math.Sin(ang)
+	x := a*math.Cos(lat1)*math.Cos(lng1) + b*math.Cos(lat2)*math.Cos(lng2)
+	y := a*math.Cos(lat1)*math.Sin(lng1) + b*math.Cos(lat2)*math.Sin(lng2)
+	z := a*math.Sin(lat1) + b*math.Sin(lat2)
+	return LatLng{degrees(math.Atan2(z, math.Hypot(x, y))), degrees(math.Atan2(y, x))}
+}
+
+// PathLength returns the total great-circle distance in meters along a path described by a series of locations.
+func PathLength(ll []LatLng) float64 {
+	var d float64
+	for i := 1; i < len(ll); i++ {
+		d += ll[i-1].Distance(ll[i])
+	}
+	return d
+}
+
+// normalizeLng returns the longitude normalized to between -180 and 180.
+func normalizeLng(lng float64) float64 {
+	if lng >= -180 && lng <= 180 {
+		return lng
+	}
+	return math.Mod(math.Mod(lng+180, 360)+360, 360) - 180
+}
@@ -0,0 +1,91 @@
+package maps
+
+import (
+	"math"
+	"testing"
+)
+
+// dms converts degrees, minutes and seconds to decimal degrees.
+func dms(d, m, s float64) float64 {
+	if d < 0 {
+		return d - m/60 - s/3600
+	}
+	return d + m/60 + s/3600
+}
+
+func near(a, b, eps float64) bool {
+	return math.Abs(a-b) <= eps
+}
+
+// Based on reference values from https://www.movable-type.co.uk/scripts/latlong.html
+func TestGeodesic(t *testing.T) {
+	landsEnd := LatLng{dms(50, 3, 59), -dms(5, 42, 53)}
+	johnOGroats := LatLng{dms(58, 38, 38), -dms(3, 4, 12)}
+
+	if d := landsEnd.Distance(johnOGroats); !near(d, 968.9e3, 100) {
+		t.Errorf("got distance %f, want 968.9km", d)
+	}
+	if b := landsEnd.Bearing(johnOGroats); !near(b, dms(9, 7, 11), 1e-3) {
+		t.Errorf("got bearing %f, want %f", b, dms(9, 7, 11))
+	}
+	if b := landsEnd.FinalBearing(johnOGroats); !near(b, dms(11, 16, 31), 1e-3) {
+		t.Errorf("got final bearing %f, want %f", b, dms(11, 16, 31))
+	}
+	m := landsEnd.Midpoint(johnOGroats)
+	if exp := (LatLng{dms(54, 21, 44), -dms(4, 31, 50)}); !near(m.Lat, exp.Lat, 1e-3) || !near(m.Lng, exp.Lng, 1e-3) {
+		t.Errorf("got midpoint %v, want %v", m, exp)
+	}
+	if d1, d2 := landsEnd.Distance(m), m.Distance(johnOGroats); !near(d1, d2, 1e-3) {
+		t.Errorf("midpoint is %fm from start and %fm from end", d1, d2)
+	}
+
+	// Antipodal points are half the Earth's circumference apart.
+	a, b := LatLng{-88.5, -180}, LatLng{88.5, 0}
+	if d := a.Distance(b); !near(d, math.Pi*EarthRadius, 1e-3) {
+		t.Errorf("got antipodal distance %f, want %f", d, math.Pi*EarthRadius)
+	}
+	if l := PathLength([]LatLng{a, b}); math.IsNaN(l) {
+		t.Errorf("got antipodal path length %f", l)
+	}
+	// The path between antipodal points heads north.
+	for _, c := range []struct {
+		from, to, mid LatLng
+	}{
+		{LatLng{0, 0}, LatLng{0, 180}, LatLng{90, 0}},
+		{LatLng{-88.5, -180}, LatLng{88.5, 0}, LatLng{1.5, -180}},
+		{LatLng{-90, 10}, LatLng{90, 10}, LatLng{0, 10}},
+	} {
+		m := c.from.Midpoint(c.to)
+		if !near(m.Lat, c.mid.Lat, 1e-6) || (math.Abs(m.Lat) < 90-1e-6 && !near(normalizeLng(m.Lng-c.mid.Lng), 0, 1e-6)) {
+			t.Errorf("got antipodal midpoint of %v and %v %v, want %v", c.from, c.to, m, c.mid)
+		}
+		if i := c.from.Interpolate(c.to, 1); !near(i.Distance(c.to), 0, 1e-3) {
+			t.Errorf("interpolated %v to %v, got %v", c.from, c.to, i)
+		}
+	}
+	if i := landsEnd.Interpolate(johnOGroats, 0.25); !near(landsEnd.Distance(i), landsEnd.Distance(johnOGroats)/4, 1e-3) {
+		t.Errorf("interpolated point %v is not a quarter of the way", i)
+	}
+	if i := landsEnd.Interpolate(johnOGroats, 1); !near(i.Lat, johnOGroats.Lat, 1e-9) || !near(i.Lng, johnOGroats.Lng, 1e-9) {
+		t.Errorf("got %v, want %v", i, johnOGroats)
+	}
+
+	start := LatLng{dms(53, 19, 14), -dms(1, 43, 47)}
+	d := start.Destination(124.8e3, dms(96, 1, 18))
+	if exp := (LatLng{dms(53, 11, 18), dms(0, 8, 0)}); !near(d.Lat, exp.Lat, 1e-3) || !near(d.Lng, exp.Lng, 1e-3) {
+		t.Errorf("got destination %v, want %v", d, exp)
+	}
+
+	// Crossing the antimeridian.
+	if d := (LatLng{0, 179}).Destination(LatLng{0, 0}.Distance(LatLng{0, 2}), 90); !near(d.Lng, -179, 1e-9) {
+		t.Errorf("got destination %v, want longitude -179", d)
+	}
+
+	ll := []LatLng{landsEnd, m, johnOGroats}
+	if l := PathLength(ll); !near(l, landsEnd.Distance(johnOGroats), 1e-3) {
+		t.Errorf("got path length %f, want %f", l, landsEnd.Distance(johnOGroats))
+	}
+	if l := PathLength(ll[:1]); l != 0 {
+		t.Errorf("got path length %f for single point, want 0", l)
+	}
+}
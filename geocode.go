@@ -31,15 +31,17 @@ const (
 	// TODO: enums for https://developers.google.com/maps/documentation/geocoding/#Types
 )
 
-// Geocode requests conversion of an address to a latitude/longitude pair.
+// Geocode requests conversion of an address to a latitude/longitude pair, using the Client configured in ctx.
 func Geocode(ctx context.Context, opts *GeocodeOpts) ([]GeocodeResult, error) {
+	return fromContext(ctx).Geocode(ctx, opts)
+}
+
+// Geocode requests conversion of an address to a latitude/longitude pair.
+func (c *Client) Geocode(ctx context.Context, opts *GeocodeOpts) ([]GeocodeResult, error) {
 	var r geocodeResponse
-	if err := doDecode(ctx, baseURL+geocode(opts), &r); err != nil {
+	if err := c.doDecode(ctx, ServiceGeocode, geocode(opts), &r); err != nil {
 		return nil, err
 	}
-	if r.Status != StatusOK {
-		return nil, APIError{r.Status, ""}
-	}
 	return r.Results, nil
 }
 
@@ -126,10 +128,13 @@ func (g *GeocodeOpts) update(p url.Values) {
 }
 
 type geocodeResponse struct {
-	Results []GeocodeResult `json:"results"`
-	Status  string          `json:"status"`
+	Results      []GeocodeResult `json:"results"`
+	Status       string          `json:"status"`
+	ErrorMessage string          `json:"error_message"`
 }
 
+func (r geocodeResponse) apiStatus() (string, string) { return r.Status, r.ErrorMessage }
+
 // GeocodeResult represents the geocoded result for the given input.
 type GeocodeResult struct {
 	// AddressComponents contains the separate address components.
@@ -190,17 +195,21 @@ type GeocodeResult struct {
 	PartialMatch string `json:"partial_match"`
 }
 
-// ReverseGeocode requests conversion of a location to its nearest address.
+// ReverseGeocode requests conversion of a location to its nearest address, using the Client configured in ctx.
 //
 // See https://developers.google.com/maps/documentation/geocoding/#ReverseGeocoding
 func ReverseGeocode(ctx context.Context, ll LatLng, opts *ReverseGeocodeOpts) ([]GeocodeResult, error) {
+	return fromContext(ctx).ReverseGeocode(ctx, ll, opts)
+}
+
+// ReverseGeocode requests conversion of a location to its nearest address.
+//
+// See https://developers.google.com/maps/documentation/geocoding/#ReverseGeocoding
+func (c *Client) ReverseGeocode(ctx context.Context, ll LatLng, opts *ReverseGeocodeOpts) ([]GeocodeResult, error) {
 	var r geocodeResponse
-	if err := doDecode(ctx, baseURL+reversegeocode(ll, opts), &r); err != nil {
+	if err := c.doDecode(ctx, ServiceGeocode, reversegeocode(ll, opts), &r); err != nil {
 		return nil, err
 	}
-	if r.Status != StatusOK {
-		return nil, APIError{r.Status, ""}
-	}
 	return r.Results, nil
 }
 
@@ -0,0 +1,334 @@
+// Package geojson converts Google Maps API results to and from GeoJSON, for use with GIS tools and web maps.
+//
+// See https://tools.ietf.org/html/rfc7946
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	maps "github.com/imjasonh/staticmaps"
+)
+
+// GeoJSON object types.
+const (
+	TypeFeatureCollection = "FeatureCollection"
+	TypeFeature           = "Feature"
+	TypePoint             = "Point"
+	TypeMultiPoint        = "MultiPoint"
+	TypeLineString        = "LineString"
+	TypeMultiLineString   = "MultiLineString"
+	TypePolygon           = "Polygon"
+	TypeMultiPolygon      = "MultiPolygon"
+)
+
+// FeatureCollection is a GeoJSON FeatureCollection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON Feature.
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+
+	// BBox, if set, is the bounding box of the feature, as [west, south, east, north].
+	BBox []float64 `json:"bbox,omitempty"`
+}
+
+// Geometry is a GeoJSON Geometry.
+//
+// Coordinates are positions of the form [longitude, latitude] or [longitude, latitude, elevation], nested according to Type:
+// []float64 for a Point, [][]float64 for a MultiPoint or LineString, [][][]float64 for a MultiLineString or Polygon,
+// and [][][][]float64 for a MultiPolygon.
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding Coordinates according to Type.
+func (g *Geometry) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	g.Type = raw.Type
+	var err error
+	switch raw.Type {
+	case TypePoint:
+		var c []float64
+		err = json.Unmarshal(raw.Coordinates, &c)
+		g.Coordinates = c
+	case TypeMultiPoint, TypeLineString:
+		var c [][]float64
+		err = json.Unmarshal(raw.Coordinates, &c)
+		g.Coordinates = c
+	case TypeMultiLineString, TypePolygon:
+		var c [][][]float64
+		err = json.Unmarshal(raw.Coordinates, &c)
+		g.Coordinates = c
+	case TypeMultiPolygon:
+		var c [][][][]float64
+		err = json.Unmarshal(raw.Coordinates, &c)
+		g.Coordinates = c
+	default:
+		return fmt.Errorf("unsupported geometry type %q", raw.Type)
+	}
+	return err
+}
+
+// Point returns a Point Geometry at the location.
+func Point(ll maps.LatLng) *Geometry {
+	return &Geometry{Type: TypePoint, Coordinates: position(ll)}
+}
+
+// LineString returns a LineString Geometry along the locations.
+func LineString(ll []maps.LatLng) *Geometry {
+	return &Geometry{Type: TypeLineString, Coordinates: positions(ll)}
+}
+
+func position(ll maps.LatLng) []float64 {
+	return []float64{ll.Lng, ll.Lat}
+}
+
+func positions(ll []maps.LatLng) [][]float64 {
+	c := make([][]float64, len(ll))
+	for i, l := range ll {
+		c[i] = position(l)
+	}
+	return c
+}
+
+func bbox(b maps.Bounds) []float64 {
+	return []float64{b.Southwest.Lng, b.Southwest.Lat, b.Northeast.Lng, b.Northeast.Lat}
+}
+
+func newCollection(fs []Feature) *FeatureCollection {
+	if fs == nil {
+		fs = []Feature{}
+	}
+	return &FeatureCollection{Type: TypeFeatureCollection, Features: fs}
+}
+
+// FromRoute returns a FeatureCollection describing the route.
+//
+// The first Feature is a LineString along the route's OverviewPolyline, with the route's summary, total distance in meters
+// and total duration in seconds as properties. It is followed by a LineString for each step of each leg of the route,
+// with the step's leg and step index, distance, duration, instructions and travel mode as properties.
+func FromRoute(r maps.Route) (*FeatureCollection, error) {
+	overview, err := r.OverviewPolyline.Decode()
+	if err != nil {
+		return nil, err
+	}
+	var steps []Feature
+	for i, leg := range r.Legs {
+		for j, s := range leg.Steps {
+			var ll []maps.LatLng
+			if s.Polyline != nil {
+				if ll, err = s.Polyline.Decode(); err != nil {
+					return nil, err
+				}
+			}
+			props := map[string]interface{}{
+				"leg":          i,
+				"step":         j,
+				"instructions": s.HTMLInstructions,
+				"travel_mode":  s.TravelMode,
+			}
+			if s.Distance != nil {
+				props["distance"] = s.Distance.Value
+			}
+			if s.Duration != nil {
+				props["duration"] = s.Duration.Value
+			}
+			if s.Maneuver != "" {
+				props["maneuver"] = s.Maneuver
+			}
+			steps = append(steps, Feature{Type: TypeFeature, Geometry: LineString(ll), Properties: props})
+		}
+	}
+	route := Feature{
+		Type:     TypeFeature,
+		Geometry: LineString(overview),
+		Properties: map[string]interface{}{
+			"summary":    r.Summary,
+			"distance":   r.Distance(),
+			"duration":   int64(r.Duration() / time.Second),
+			"copyrights": r.Copyrights,
+		},
+		BBox: bbox(r.Bounds),
+	}
+	return newCollection(append([]Feature{route}, steps...)), nil
+}
+
+// FromGeocodeResults returns a FeatureCollection containing a Point for each result, with its formatted address,
+// address components, types and location type as properties, and its viewport as its bounding box.
+func FromGeocodeResults(rs []maps.GeocodeResult) *FeatureCollection {
+	var fs []Feature
+	for _, r := range rs {
+		fs = append(fs, Feature{
+			Type:     TypeFeature,
+			Geometry: Point(r.Geometry.Location),
+			Properties: map[string]interface{}{
+				"formatted_address":  r.FormattedAddress,
+				"address_components": r.AddressComponents,
+				"types":              r.Types,
+				"location_type":      r.Geometry.LocationType,
+			},
+			BBox: bbox(r.Geometry.Viewport),
+		})
+	}
+	return newCollection(fs)
+}
+
+// FromElevationResults returns a FeatureCollection containing a Point for each result, whose position includes its elevation,
+// with its elevation and resolution in meters as properties.
+func FromElevationResults(rs []maps.ElevationResult) *FeatureCollection {
+	var fs []Feature
+	for _, r := range rs {
+		fs = append(fs, Feature{
+			Type:     TypeFeature,
+			Geometry: &Geometry{Type: TypePoint, Coordinates: []float64{r.Location.Lng, r.Location.Lat, r.Elevation}},
+			Properties: map[string]interface{}{
+				"elevation":  r.Elevation,
+				"resolution": r.Resolution,
+			},
+		})
+	}
+	return newCollection(fs)
+}
+
+// FromSnappedPoints returns a FeatureCollection containing a Point for each snapped point, with its place ID and,
+// if it was not interpolated, its original index as properties.
+func FromSnappedPoints(ps []maps.SnappedPoint) *FeatureCollection {
+	var fs []Feature
+	for _, p := range ps {
+		props := map[string]interface{}{"place_id": p.PlaceID}
+		if p.OriginalIndex != nil {
+			props["original_index"] = *p.OriginalIndex
+		}
+		fs = append(fs, Feature{
+			Type:       TypeFeature,
+			Geometry:   Point(maps.LatLng{Lat: p.Location.Latitude, Lng: p.Location.Longitude}),
+			Properties: props,
+		})
+	}
+	return newCollection(fs)
+}
+
+// Decode reads a GeoJSON FeatureCollection, Feature or Geometry from r, and returns it as a FeatureCollection.
+func Decode(r io.Reader) (*FeatureCollection, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	var t struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, err
+	}
+	switch t.Type {
+	case TypeFeatureCollection:
+		var fc FeatureCollection
+		err := json.Unmarshal(raw, &fc)
+		return &fc, err
+	case TypeFeature:
+		var f Feature
+		err := json.Unmarshal(raw, &f)
+		return newCollection([]Feature{f}), err
+	default:
+		var g Geometry
+		err := json.Unmarshal(raw, &g)
+		return newCollection([]Feature{{Type: TypeFeature, Geometry: &g}}), err
+	}
+}
+
+// Overlays returns Markers and Paths equivalent to the features of the collection, suitable for StaticMapOpts.
+//
+// Points and MultiPoints are returned as a single group of Markers. LineStrings and MultiLineStrings are returned as a Path
+// for each line, and Polygons and MultiPolygons as a Path for each ring. Features without a geometry are ignored.
+func (fc *FeatureCollection) Overlays() ([]maps.Markers, []maps.Path, error) {
+	var points []maps.Location
+	var paths []maps.Path
+	addPath := func(c [][]float64) error {
+		ls, err := locations(c)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, maps.Path{Locations: ls})
+		return nil
+	}
+	for _, f := range fc.Features {
+		if f.Geometry == nil {
+			continue
+		}
+		var err error
+		switch c := f.Geometry.Coordinates.(type) {
+		case []float64:
+			var ll maps.LatLng
+			if ll, err = latLng(c); err == nil {
+				points = append(points, ll)
+			}
+		case [][]float64:
+			if f.Geometry.Type == TypeMultiPoint {
+				var ls []maps.Location
+				if ls, err = locations(c); err == nil {
+					points = append(points, ls...)
+				}
+			} else {
+				err = addPath(c)
+			}
+		case [][][]float64:
+			for _, l := range c {
+				if err := addPath(l); err != nil {
+					return nil, nil, err
+				}
+			}
+		case [][][][]float64:
+			for _, p := range c {
+				for _, l := range p {
+					if err := addPath(l); err != nil {
+						return nil, nil, err
+					}
+				}
+			}
+		default:
+			err = fmt.Errorf("unsupported geometry type %q", f.Geometry.Type)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	var markers []maps.Markers
+	if len(points) > 0 {
+		markers = []maps.Markers{{Locations: points}}
+	}
+	return markers, paths, nil
+}
+
+func latLng(c []float64) (maps.LatLng, error) {
+	if len(c) < 2 {
+		return maps.LatLng{}, fmt.Errorf("invalid position %v", c)
+	}
+	return maps.LatLng{Lat: c[1], Lng: c[0]}, nil
+}
+
+func locations(c [][]float64) ([]maps.Location, error) {
+	ls := make([]maps.Location, len(c))
+	for i, p := range c {
+		ll, err := latLng(p)
+		if err != nil {
+			return nil, err
+		}
+		ls[i] = ll
+	}
+	return ls, nil
+}
@@ -0,0 +1,157 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	maps "github.com/imjasonh/staticmaps"
+)
+
+const routeJSON = `{
+  "summary": "I-80",
+  "copyrights": "Map data",
+  "bounds": {"northeast": {"lat": 43.252, "lng": -120.2}, "southwest": {"lat": 38.5, "lng": -126.453}},
+  "overview_polyline": {"points": "_p~iF~ps|U_ulLnnqC_mqNvxq` + "`" + `@"},
+  "legs": [{
+    "distance": {"text": "2 km", "value": 2000},
+    "duration": {"text": "3 mins", "value": 180},
+    "steps": [{
+      "travel_mode": "DRIVING",
+      "html_instructions": "Head <b>north</b>",
+      "distance": {"text": "1 km", "value": 1200},
+      "duration": {"text": "2 mins", "value": 100},
+      "polyline": {"points": "_p~iF~ps|U_ulLnnqC"}
+    }, {
+      "travel_mode": "DRIVING",
+      "html_instructions": "Turn <b>left</b>",
+      "maneuver": "turn-left",
+      "distance": {"text": "1 km", "value": 800},
+      "duration": {"text": "1 min", "value": 80},
+      "polyline": {"points": "_mqNvxq` + "`" + `@"}
+    }]
+  }]
+}`
+
+func TestFromRoute(t *testing.T) {
+	var r maps.Route
+	if err := json.Unmarshal([]byte(routeJSON), &r); err != nil {
+		t.Fatal(err)
+	}
+	fc, err := FromRoute(r)
+	if err != nil {
+		t.Fatalf("FromRoute: %v", err)
+	}
+	if len(fc.Features) != 3 {
+		t.Fatalf("got %d features, want 3", len(fc.Features))
+	}
+	overview := fc.Features[0]
+	if got, want := overview.Geometry.Coordinates, [][]float64{{-120.2, 38.5}, {-120.95, 40.7}, {-126.453, 43.252}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got overview coordinates %v, want %v", got, want)
+	}
+	if got := overview.Properties["distance"]; got != int64(2000) {
+		t.Errorf("got route distance %v, want 2000", got)
+	}
+	if got, want := overview.BBox, []float64{-126.453, 38.5, -120.2, 43.252}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got bbox %v, want %v", got, want)
+	}
+	step := fc.Features[2]
+	if step.Properties["maneuver"] != "turn-left" || step.Properties["duration"] != int64(80) || step.Properties["step"] != 1 {
+		t.Errorf("got step properties %v", step.Properties)
+	}
+
+	// The result round-trips through JSON, and back into overlays.
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(fc); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	markers, paths, err := decoded.Overlays()
+	if err != nil {
+		t.Fatalf("Overlays: %v", err)
+	}
+	if len(markers) != 0 || len(paths) != 3 {
+		t.Errorf("got %d markers, %d paths; want 0, 3", len(markers), len(paths))
+	}
+	if got, want := paths[0].Locations[1], (maps.LatLng{Lat: 40.7, Lng: -120.95}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFromResults(t *testing.T) {
+	idx := 3
+	fc := FromSnappedPoints([]maps.SnappedPoint{{Location: maps.SnappedLocation{Latitude: 1, Longitude: 2}, PlaceID: "abc", OriginalIndex: &idx}})
+	if got, want := fc.Features[0].Geometry.Coordinates, []float64{2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if fc.Features[0].Properties["original_index"] != 3 {
+		t.Errorf("got properties %v", fc.Features[0].Properties)
+	}
+
+	fc = FromElevationResults([]maps.ElevationResult{{Elevation: 12.5, Location: maps.LatLng{Lat: 1, Lng: 2}, Resolution: 4}})
+	if got, want := fc.Features[0].Geometry.Coordinates, []float64{2, 1, 12.5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Empty results encode as an empty array, not null.
+	b, err := json.Marshal(FromGeocodeResults(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"type":"FeatureCollection","features":[]}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestOverlays(t *testing.T) {
+	for _, c := range []struct {
+		in             string
+		markers, paths int
+		wantErr        bool
+	}{{
+		in:      `{"type": "Point", "coordinates": [2, 1]}`,
+		markers: 1,
+	}, {
+		in:      `{"type": "Feature", "geometry": {"type": "MultiPoint", "coordinates": [[2, 1], [4, 3]]}, "properties": null}`,
+		markers: 1,
+	}, {
+		in: `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]], [[0.2, 0.2], [0.4, 0.2], [0.4, 0.4], [0.2, 0.2]]]}},
+			{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 1]]}},
+			{"type": "Feature", "geometry": null}
+		]}`,
+		paths: 3,
+	}, {
+		in:      `{"type": "LineString", "coordinates": [[0], [1, 1]]}`,
+		wantErr: true,
+	}, {
+		// An invalid ring is an error, even when followed by valid ones.
+		in:      `{"type": "MultiPolygon", "coordinates": [[[[0, 0], [1], [0, 0]]], [[[0, 0], [1, 0], [1, 1], [0, 0]]]]}`,
+		wantErr: true,
+	}, {
+		in:      `{"type": "MultiLineString", "coordinates": [[[0, 0], [1]], [[0, 0], [1, 1]]]}`,
+		wantErr: true,
+	}, {
+		in:      `{"type": "GeometryCollection", "geometries": []}`,
+		wantErr: true,
+	}} {
+		fc, err := Decode(strings.NewReader(c.in))
+		if err == nil {
+			var markers []maps.Markers
+			var paths []maps.Path
+			if markers, paths, err = fc.Overlays(); err == nil {
+				if len(markers) != c.markers || len(paths) != c.paths {
+					t.Errorf("%s: got %d markers, %d paths; want %d, %d", c.in, len(markers), len(paths), c.markers, c.paths)
+				}
+			}
+		}
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: got error %v, want error %t", c.in, err, c.wantErr)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package gpx reads and writes GPS Exchange Format (GPX) files, to exchange tracks and routes with GPS devices.
+//
+// See http://www.topografix.com/GPX/1/1/
+package gpx
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+
+	maps "github.com/imjasonh/staticmaps"
+)
+
+const (
+	namespace = "http://www.topografix.com/GPX/1/1"
+	creator   = "github.com/imjasonh/staticmaps"
+)
+
+type gpx struct {
+	XMLName   xml.Name `xml:"gpx"`
+	Xmlns     string   `xml:"xmlns,attr,omitempty"`
+	Version   string   `xml:"version,attr"`
+	Creator   string   `xml:"creator,attr"`
+	Waypoints []wpt    `xml:"wpt"`
+	Routes    []rte    `xml:"rte"`
+	Tracks    []trk    `xml:"trk"`
+}
+
+type wpt struct {
+	Lat  float64    `xml:"lat,attr"`
+	Lon  float64    `xml:"lon,attr"`
+	Ele  *float64   `xml:"ele,omitempty"`
+	Time *time.Time `xml:"time,omitempty"`
+	Name string     `xml:"name,omitempty"`
+}
+
+type rte struct {
+	Name   string `xml:"name,omitempty"`
+	Points []wpt  `xml:"rtept"`
+}
+
+type trk struct {
+	Name     string   `xml:"name,omitempty"`
+	Segments []trkseg `xml:"trkseg"`
+}
+
+type trkseg struct {
+	Points []wpt `xml:"trkpt"`
+}
+
+// Point is a point of a track or route read from a GPX file.
+type Point struct {
+	maps.LatLng
+
+	// Time is the time at which the point was recorded, or the zero Time if it was not recorded.
+	Time time.Time
+
+	// Elevation is the elevation of the point in meters, or nil if it was not recorded.
+	Elevation *float64
+}
+
+// Read reads the points of every track and route in the GPX file read from r, in order.
+//
+// Tracks are read before routes, and the segments of each track are concatenated. Waypoints are ignored.
+func Read(r io.Reader) ([]Point, error) {
+	var g gpx
+	if err := xml.NewDecoder(r).Decode(&g); err != nil {
+		return nil, err
+	}
+	var ps []Point
+	add := func(ws []wpt) {
+		for _, w := range ws {
+			p := Point{LatLng: maps.LatLng{Lat: w.Lat, Lng: w.Lon}, Elevation: w.Ele}
+			if w.Time != nil {
+				p.Time = *w.Time
+			}
+			ps = append(ps, p)
+		}
+	}
+	for _, t := range g.Tracks {
+		for _, s := range t.Segments {
+			add(s.Points)
+		}
+	}
+	for _, r := range g.Routes {
+		add(r.Points)
+	}
+	return ps, nil
+}
+
+// LatLngs returns the locations of the points, e.g., to request SnapToRoads or ElevationPath.
+func LatLngs(ps []Point) []maps.LatLng {
+	ll := make([]maps.LatLng, len(ps))
+	for i, p := range ps {
+		ll[i] = p.LatLng
+	}
+	return ll
+}
+
+// WriteOpts defines options for writing GPX files.
+type WriteOpts struct {
+	// Name is the name of the route or track.
+	Name string
+
+	// Track, if true, writes the route as a track following the path of each step, instead of a route of turn-by-turn points.
+	Track bool
+
+	// Elevations, if supplied, are used to include the elevation of each point, e.g., from ElevationPath along the route.
+	//
+	// Each point is given the elevation of the nearest result, since results such as those from ElevationPath are sampled
+	// along the path rather than at each point. This takes time proportional to the number of points multiplied by the number of results.
+	Elevations []maps.ElevationResult
+}
+
+// WriteRoute writes the route to w as a GPX file.
+//
+// By default, the route is written as a <rte> containing a point at the start of each step, named with the step's plain-text instructions,
+// followed by the end of each leg, named with the leg's end address. If opts.Track is true, it is instead written as a <trk>
+// with a point for each location along the path of each step.
+func WriteRoute(w io.Writer, r maps.Route, opts *WriteOpts) error {
+	if opts == nil {
+		opts = &WriteOpts{}
+	}
+	g := gpx{Xmlns: namespace, Version: "1.1", Creator: creator}
+	if opts.Track {
+		path, err := r.FullPath()
+		if err != nil {
+			return err
+		}
+		var seg trkseg
+		for _, ll := range path {
+			seg.Points = append(seg.Points, wpt{Lat: ll.Lat, Lon: ll.Lng})
+		}
+		opts.setElevations(seg.Points)
+		g.Tracks = []trk{{Name: opts.Name, Segments: []trkseg{seg}}}
+	} else {
+		rt := rte{Name: opts.Name}
+		for _, leg := range r.Legs {
+			for _, s := range leg.Steps {
+				if s.StartLocation != nil {
+					rt.Points = append(rt.Points, wpt{Lat: s.StartLocation.Lat, Lon: s.StartLocation.Lng, Name: s.Instructions().Text})
+				}
+			}
+			if leg.EndLocation != nil {
+				rt.Points = append(rt.Points, wpt{Lat: leg.EndLocation.Lat, Lon: leg.EndLocation.Lng, Name: leg.EndAddress})
+			}
+		}
+		opts.setElevations(rt.Points)
+		g.Routes = []rte{rt}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(g); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// setElevations sets the elevation of each point from o.Elevations, as described by WriteOpts.Elevations.
+func (o *WriteOpts) setElevations(ps []wpt) {
+	if len(o.Elevations) == 0 {
+		return
+	}
+	for i := range ps {
+		ll := maps.LatLng{Lat: ps[i].Lat, Lng: ps[i].Lon}
+		e := o.Elevations[0]
+		nearest := ll.Distance(e.Location)
+		for _, c := range o.Elevations[1:] {
+			if d := ll.Distance(c.Location); d < nearest {
+				e, nearest = c, d
+			}
+		}
+		ele := e.Elevation
+		ps[i].Ele = &ele
+	}
+}
@@ -0,0 +1,150 @@
+package gpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	maps "github.com/imjasonh/staticmaps"
+)
+
+const trackGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="1" lon="1"><name>ignored</name></wpt>
+  <trk>
+    <name>Morning walk</name>
+    <trkseg>
+      <trkpt lat="47.644548" lon="-122.326897"><ele>4.46</ele><time>2009-10-17T18:37:26Z</time></trkpt>
+      <trkpt lat="47.644549" lon="-122.326898"><ele>4.94</ele><time>2009-10-17T18:37:31Z</time></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="47.644550" lon="-122.326899"></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestRead(t *testing.T) {
+	ps, err := Read(strings.NewReader(trackGPX))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(ps) != 3 {
+		t.Fatalf("got %d points, want 3", len(ps))
+	}
+	if got, want := ps[1].LatLng, (maps.LatLng{Lat: 47.644549, Lng: -122.326898}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := ps[1].Time, time.Date(2009, 10, 17, 18, 37, 31, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got time %v, want %v", got, want)
+	}
+	if ps[0].Elevation == nil || *ps[0].Elevation != 4.46 {
+		t.Errorf("got elevation %v, want 4.46", ps[0].Elevation)
+	}
+	if !ps[2].Time.IsZero() || ps[2].Elevation != nil {
+		t.Errorf("got time %v and elevation %v, want neither", ps[2].Time, ps[2].Elevation)
+	}
+	if ll := LatLngs(ps); len(ll) != 3 || ll[2] != ps[2].LatLng {
+		t.Errorf("got LatLngs %v", ll)
+	}
+
+	if _, err := Read(strings.NewReader("<gpx><trk>")); err == nil {
+		t.Error("expected error reading truncated file")
+	}
+}
+
+const routeJSON = `{
+  "legs": [{
+    "end_location": {"lat": 43.252, "lng": -126.453},
+    "end_address": "Destination",
+    "steps": [{
+      "start_location": {"lat": 38.5, "lng": -120.2},
+      "html_instructions": "Head north",
+      "polyline": {"points": "_p~iF~ps|U_ulLnnqC"}
+    }, {
+      "start_location": {"lat": 40.7, "lng": -120.95},
+      "html_instructions": "Turn <b>left</b>",
+      "polyline": {"points": "_flwFn` + "`" + `faVwrq@` + "`" + `qoA"}
+    }]
+  }]
+}`
+
+func TestWriteRoute(t *testing.T) {
+	var r maps.Route
+	if err := json.Unmarshal([]byte(routeJSON), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRoute(&buf, r, &WriteOpts{Name: "Trip"}); err != nil {
+		t.Fatalf("WriteRoute: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`xmlns="http://www.topografix.com/GPX/1/1"`,
+		`<rte>`,
+		`<name>Trip</name>`,
+		`<rtept lat="40.7" lon="-120.95">`,
+		`<name>Turn left</name>`,
+		`<name>Destination</name>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	ps, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(ps) != 3 {
+		t.Errorf("got %d route points, want 3", len(ps))
+	}
+
+	buf.Reset()
+	if err := WriteRoute(&buf, r, &WriteOpts{
+		Track: true,
+		Elevations: []maps.ElevationResult{
+			{Location: maps.LatLng{Lat: 38.5, Lng: -120.2}, Elevation: 100},
+			{Location: maps.LatLng{Lat: 41, Lng: -121.4}, Elevation: 200},
+		},
+	}); err != nil {
+		t.Fatalf("WriteRoute: %v", err)
+	}
+	if !strings.Contains(buf.String(), "<trkseg>") {
+		t.Errorf("output does not contain a track:\n%s", buf.String())
+	}
+	ps, err = Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	// The joint between the steps is not repeated.
+	if len(ps) != 3 {
+		t.Fatalf("got %d track points, want 3", len(ps))
+	}
+	for i, want := range []float64{100, 200, 200} {
+		if ps[i].Elevation == nil || *ps[i].Elevation != want {
+			t.Errorf("point %d: got elevation %v, want %f", i, ps[i].Elevation, want)
+		}
+	}
+
+	// Even with one result for each point, elevations are matched by distance rather than by index.
+	buf.Reset()
+	if err := WriteRoute(&buf, r, &WriteOpts{
+		Elevations: []maps.ElevationResult{
+			{Location: maps.LatLng{Lat: 43.252, Lng: -126.453}, Elevation: 3},
+			{Location: maps.LatLng{Lat: 38.5, Lng: -120.2}, Elevation: 1},
+			{Location: maps.LatLng{Lat: 40.7, Lng: -120.95}, Elevation: 2},
+		},
+	}); err != nil {
+		t.Fatalf("WriteRoute: %v", err)
+	}
+	if ps, err = Read(&buf); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	for i, want := range []float64{1, 2, 3} {
+		if ps[i].Elevation == nil || *ps[i].Elevation != want {
+			t.Errorf("point %d: got elevation %v, want %f", i, ps[i].Elevation, want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package maps
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+)
+
+// Instructions are the turn-by-turn instructions for a step, converted from its HTMLInstructions.
+type Instructions struct {
+	// Text contains the primary instructions as plain text, e.g., "Turn left onto Main St".
+	Text string
+
+	// Markdown contains the primary instructions as Markdown, with emphasized text in bold, e.g., "Turn **left** onto **Main St**".
+	Markdown string
+
+	// Notes contains any secondary notes as plain text, e.g., "Toll road" or "Destination will be on the right".
+	Notes []string
+}
+
+// Instructions returns the step's HTMLInstructions converted to plain text and Markdown.
+func (s Step) Instructions() Instructions {
+	return ParseInstructions(s.HTMLInstructions)
+}
+
+// ParseInstructions converts instructions formatted as HTML, such as a step's HTMLInstructions, to plain text and Markdown.
+//
+// Text within <div> elements is returned separately as Notes, and all other tags are removed.
+func ParseInstructions(s string) Instructions {
+	var text, md, note strings.Builder
+	var notes []string
+	depth := 0
+	for s != "" {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			i = len(s)
+		}
+		if t := html.UnescapeString(s[:i]); depth > 0 {
+			note.WriteString(t)
+		} else {
+			text.WriteString(t)
+			md.WriteString(markdownEscaper.Replace(t))
+		}
+		s = s[i:]
+		if s == "" {
+			break
+		}
+		j := strings.IndexByte(s, '>')
+		if j < 0 {
+			// An unterminated tag is dropped.
+			break
+		}
+		closing, name := tagName(s[1:j])
+		s = s[j+1:]
+		switch name {
+		case "div":
+			if closing {
+				if depth > 0 {
+					depth--
+				}
+			} else {
+				depth++
+			}
+			if n := collapseSpace(note.String()); n != "" {
+				notes = append(notes, n)
+			}
+			note.Reset()
+			// Separate any text following the note from the text preceding it.
+			text.WriteByte(' ')
+			md.WriteByte(' ')
+		case "b", "strong":
+			if depth == 0 {
+				md.WriteString("**")
+			}
+		case "br":
+			text.WriteByte(' ')
+			md.WriteByte(' ')
+		}
+	}
+	return Instructions{
+		Text:     collapseSpace(text.String()),
+		Markdown: strings.ReplaceAll(collapseSpace(md.String()), "****", ""),
+		Notes:    notes,
+	}
+}
+
+// markdownEscaper escapes Markdown syntax in text, including angle brackets which would otherwise be rendered as HTML.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `_`, `\_`, "`", "\\`", `[`, `\[`, `]`, `\]`, `#`, `\#`, `<`, `\<`, `>`, `\>`)
+
+// tagName returns the lowercase name of the HTML tag, e.g., "div" for `div style="font-size:0.9em"`, and whether it is a closing tag.
+func tagName(tag string) (closing bool, name string) {
+	tag = strings.TrimSpace(tag)
+	if strings.HasPrefix(tag, "/") {
+		closing = true
+		tag = tag[1:]
+	}
+	if i := strings.IndexAny(tag, " \t\n/"); i >= 0 {
+		tag = tag[:i]
+	}
+	return closing, strings.ToLower(tag)
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Itinerary returns the route as a plain-text numbered list of instructions, suitable for SMS and email.
+//
+// Each step's instructions are followed by its distance and duration, and any notes on separate lines.
+// Each leg is preceded by its start and end addresses, and the list is followed by the total distance and duration of the route, in the same units as its legs.
+func (r Route) Itinerary() string {
+	var b strings.Builder
+	n := 0
+	for _, l := range r.Legs {
+		if l.StartAddress != "" || l.EndAddress != "" {
+			fmt.Fprintf(&b, "%s to %s\n", l.StartAddress, l.EndAddress)
+		}
+		for _, s := range l.Steps {
+			n++
+			in := s.Instructions()
+			fmt.Fprintf(&b, "%d. %s", n, in.Text)
+			var stats []string
+			if s.Distance != nil && s.Distance.Text != "" {
+				stats = append(stats, s.Distance.Text)
+			}
+			if s.Duration != nil && s.Duration.Text != "" {
+				stats = append(stats, s.Duration.Text)
+			}
+			if len(stats) > 0 {
+				fmt.Fprintf(&b, " (%s)", strings.Join(stats, ", "))
+			}
+			b.WriteByte('\n')
+			for _, note := range in.Notes {
+				fmt.Fprintf(&b, "   %s\n", note)
+			}
+		}
+	}
+	if len(r.Legs) == 1 && r.Legs[0].Distance != nil && r.Legs[0].Duration != nil {
+		// Prefer the API's localized text.
+		fmt.Fprintf(&b, "Total: %s, %s\n", r.Legs[0].Distance.Text, r.Legs[0].Duration.Text)
+	} else if len(r.Legs) > 0 {
+		fmt.Fprintf(&b, "Total: %s, %s\n", formatDistance(r.Distance(), r.imperial()), formatDuration(r.Duration()))
+	}
+	return b.String()
+}
+
+// imperial reports whether the route's distances are described in imperial units, i.e., whether it was requested with UnitImperial.
+func (r Route) imperial() bool {
+	for _, l := range r.Legs {
+		if l.Distance != nil {
+			if t := l.Distance.Text; strings.HasSuffix(t, " mi") || strings.HasSuffix(t, " ft") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatDistance formats a distance in meters, in miles or feet if imperial is true, and otherwise in kilometers or meters.
+func formatDistance(m int64, imperial bool) string {
+	if imperial {
+		if ft := float64(m) / 0.3048; ft < 528 {
+			return fmt.Sprintf("%d ft", int64(math.Round(ft)))
+		}
+		return fmt.Sprintf("%.1f mi", float64(m)/1609.344)
+	}
+	if m < 1000 {
+		return fmt.Sprintf("%d m", m)
+	}
+	return fmt.Sprintf("%.1f km", float64(m)/1000)
+}
+
+func formatDuration(d time.Duration) string {
+	mins := int64(d.Round(time.Minute) / time.Minute)
+	h, m := mins/60, mins%60
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	if h == 0 {
+		return plural(m, "min")
+	}
+	if m == 0 {
+		return plural(h, "hour")
+	}
+	return plural(h, "hour") + " " + plural(m, "min")
+}
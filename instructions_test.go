@@ -0,0 +1,108 @@
+package maps
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseInstructions(t *testing.T) {
+	for _, c := range []struct {
+		in  string
+		exp Instructions
+	}{{
+		in:  "Head <b>north</b> on <b>Main St</b> toward <b>1st Ave</b>",
+		exp: Instructions{Text: "Head north on Main St toward 1st Ave", Markdown: "Head **north** on **Main St** toward **1st Ave**"},
+	}, {
+		in: `Turn <b>right</b> onto <b>I-80 E</b><div style="font-size:0.9em">Toll road</div><div style="font-size:0.9em">Destination will be on the <b>left</b></div>`,
+		exp: Instructions{
+			Text:     "Turn right onto I-80 E",
+			Markdown: "Turn **right** onto **I-80 E**",
+			Notes:    []string{"Toll road", "Destination will be on the left"},
+		},
+	}, {
+		in:  "Take exit <b>12</b> for <b>Smith &amp; Jones Rd</b>/<wbr/><b>CA-1</b>",
+		exp: Instructions{Text: "Take exit 12 for Smith & Jones Rd/CA-1", Markdown: "Take exit **12** for **Smith & Jones Rd**/**CA-1**"},
+	}, {
+		// Markdown syntax in the text is escaped.
+		in:  "Continue onto <B>*Star_Way*</B><br>then stop",
+		exp: Instructions{Text: "Continue onto *Star_Way* then stop", Markdown: `Continue onto **\*Star\_Way\*** then stop`},
+	}, {
+		// Entity-encoded angle brackets are not rendered as HTML.
+		in:  "Turn onto <b>&lt;script&gt;</b> Rd",
+		exp: Instructions{Text: "Turn onto <script> Rd", Markdown: `Turn onto **\<script\>** Rd`},
+	}, {
+		in:  "Unterminated <b",
+		exp: Instructions{Text: "Unterminated", Markdown: "Unterminated"},
+	}, {
+		in:  "",
+		exp: Instructions{},
+	}} {
+		if got := ParseInstructions(c.in); !reflect.DeepEqual(got, c.exp) {
+			t.Errorf("ParseInstructions(%q)\n got %#v\nwant %#v", c.in, got, c.exp)
+		}
+	}
+}
+
+func TestItinerary(t *testing.T) {
+	const in = `{
+		"legs": [{
+			"start_address": "Home",
+			"end_address": "Work",
+			"distance": {"text": "1.2 km", "value": 1200},
+			"duration": {"text": "3 mins", "value": 180},
+			"steps": [{
+				"html_instructions": "Head <b>north</b>",
+				"distance": {"text": "0.4 km", "value": 400},
+				"duration": {"text": "1 min", "value": 60}
+			}, {
+				"html_instructions": "Turn <b>left</b><div>Destination will be on the right</div>",
+				"distance": {"text": "0.8 km", "value": 800},
+				"duration": {"text": "2 mins", "value": 120}
+			}]
+		}]
+	}`
+	var r Route
+	if err := json.Unmarshal([]byte(in), &r); err != nil {
+		t.Fatal(err)
+	}
+	exp := `Home to Work
+1. Head north (0.4 km, 1 min)
+2. Turn left (0.8 km, 2 mins)
+   Destination will be on the right
+Total: 1.2 km, 3 mins
+`
+	if got := r.Itinerary(); got != exp {
+		t.Errorf("got\n%s\nwant\n%s", got, exp)
+	}
+
+	// With multiple legs, the total is computed.
+	r.Legs = append(r.Legs, Leg{
+		Distance: &Distance{Value: 60000},
+		Duration: &Duration{Value: 3600},
+		Steps:    []Step{{HTMLInstructions: "Keep going"}},
+	})
+	exp = `Home to Work
+1. Head north (0.4 km, 1 min)
+2. Turn left (0.8 km, 2 mins)
+   Destination will be on the right
+3. Keep going
+Total: 61.2 km, 1 hour 3 mins
+`
+	if got := r.Itinerary(); got != exp {
+		t.Errorf("got\n%s\nwant\n%s", got, exp)
+	}
+
+	// The total is in imperial units if the legs are.
+	r = Route{Legs: []Leg{
+		{Distance: &Distance{Text: "0.5 mi", Value: 805}, Duration: &Duration{Value: 60}},
+		{Distance: &Distance{Text: "1.0 mi", Value: 1609}, Duration: &Duration{Value: 120}},
+	}}
+	if got, exp := r.Itinerary(), "Total: 1.5 mi, 3 mins\n"; got != exp {
+		t.Errorf("got %q, want %q", got, exp)
+	}
+	r.Legs[0].Distance, r.Legs[1].Distance = &Distance{Text: "300 ft", Value: 91}, &Distance{Text: "200 ft", Value: 61}
+	if got, exp := r.Itinerary(), "Total: 499 ft, 3 mins\n"; got != exp {
+		t.Errorf("got %q, want %q", got, exp)
+	}
+}
@@ -0,0 +1,344 @@
+// Package kml writes Keyhole Markup Language (KML) documents, to view routes, places and map overlays in Google Earth.
+//
+// See https://developers.google.com/kml/documentation/kmlreference
+package kml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	maps "github.com/imjasonh/staticmaps"
+)
+
+// Default styles, matching the defaults of the Static Maps API.
+var (
+	defaultRouteColor  = color.RGBA{0x00, 0x00, 0xFF, 0xBF}
+	defaultMarkerColor = color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+)
+
+const (
+	defaultWeight = 5
+	defaultIcon   = "http://maps.google.com/mapfiles/kml/paddle/wht-blank.png"
+)
+
+type kml struct {
+	XMLName  xml.Name `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Document document `xml:"Document"`
+}
+
+type document struct {
+	Name       string      `xml:"name,omitempty"`
+	Styles     []style     `xml:"Style"`
+	Folders    []folder    `xml:"Folder"`
+	Placemarks []placemark `xml:"Placemark"`
+}
+
+type folder struct {
+	Name       string      `xml:"name,omitempty"`
+	Placemarks []placemark `xml:"Placemark"`
+}
+
+type style struct {
+	ID        string     `xml:"id,attr"`
+	IconStyle *iconStyle `xml:"IconStyle"`
+	LineStyle *lineStyle `xml:"LineStyle"`
+	PolyStyle *polyStyle `xml:"PolyStyle"`
+}
+
+type iconStyle struct {
+	Color string  `xml:"color"`
+	Scale float64 `xml:"scale"`
+	Href  string  `xml:"Icon>href"`
+}
+
+type lineStyle struct {
+	Color string `xml:"color"`
+	Width int    `xml:"width"`
+}
+
+type polyStyle struct {
+	Color string `xml:"color"`
+}
+
+type placemark struct {
+	Name        string      `xml:"name,omitempty"`
+	Description string      `xml:"description,omitempty"`
+	StyleURL    string      `xml:"styleUrl,omitempty"`
+	Address     string      `xml:"address,omitempty"`
+	Point       *point      `xml:"Point"`
+	LineString  *lineString `xml:"LineString"`
+	Polygon     *polygon    `xml:"Polygon"`
+}
+
+type point struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type lineString struct {
+	Tessellate  int    `xml:"tessellate,omitempty"`
+	Coordinates string `xml:"coordinates"`
+}
+
+type polygon struct {
+	Tessellate  int    `xml:"tessellate,omitempty"`
+	Coordinates string `xml:"outerBoundaryIs>LinearRing>coordinates"`
+}
+
+// Document is a KML document, to which routes, places and map overlays can be added.
+type Document struct {
+	// Name is the name of the document.
+	Name string
+
+	doc    document
+	styles map[styleKey]string
+}
+
+// NewDocument returns a new empty Document with the given name.
+func NewDocument(name string) *Document {
+	return &Document{Name: name}
+}
+
+// Encode writes the Document to w.
+func (d *Document) Encode(w io.Writer) error {
+	k := kml{Document: d.doc}
+	k.Document.Name = d.Name
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(k); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// styleKey identifies a style by the values of its substyles, rather than by their pointers.
+type styleKey struct {
+	icon, line, poly bool
+	iconStyle        iconStyle
+	lineStyle        lineStyle
+	polyStyle        polyStyle
+}
+
+func (s style) key() styleKey {
+	var k styleKey
+	if s.IconStyle != nil {
+		k.icon, k.iconStyle = true, *s.IconStyle
+	}
+	if s.LineStyle != nil {
+		k.line, k.lineStyle = true, *s.LineStyle
+	}
+	if s.PolyStyle != nil {
+		k.poly, k.polyStyle = true, *s.PolyStyle
+	}
+	return k
+}
+
+// style adds the style to the document, if an identical style has not already been added, and returns its URL.
+func (d *Document) style(s style) string {
+	if d.styles == nil {
+		d.styles = map[styleKey]string{}
+	}
+	k := s.key()
+	id, ok := d.styles[k]
+	if !ok {
+		id = fmt.Sprintf("style%d", len(d.styles))
+		d.styles[k] = id
+		s.ID = id
+		d.doc.Styles = append(d.doc.Styles, s)
+	}
+	return "#" + id
+}
+
+func (d *Document) lineStyle(c color.Color, weight int) string {
+	return d.style(style{LineStyle: &lineStyle{Color: kmlColor(c), Width: weight}})
+}
+
+// AddRoute adds the route to the document, as a folder for each leg containing a LineString along its steps.
+//
+// Transit steps are drawn as separate LineStrings, in the color of their transit line where one is available.
+func (d *Document) AddRoute(r maps.Route) error {
+	def := d.lineStyle(defaultRouteColor, defaultWeight)
+	for _, leg := range r.Legs {
+		f := folder{Name: leg.StartAddress + " to " + leg.EndAddress}
+		var run placemark
+		var coords []maps.LatLng
+		flush := func() {
+			if len(coords) > 0 {
+				run.LineString = &lineString{Coordinates: coordinates(coords)}
+				f.Placemarks = append(f.Placemarks, run)
+			}
+			coords = nil
+		}
+		for _, s := range leg.Steps {
+			if s.Polyline == nil {
+				continue
+			}
+			ll, err := s.Polyline.Decode()
+			if err != nil {
+				return err
+			}
+			pm := placemark{Name: r.Summary, StyleURL: def}
+			if td := s.TransitDetails; td != nil {
+				pm.Name = td.Line.ShortName
+				if pm.Name == "" {
+					pm.Name = td.Line.Name
+				}
+				pm.Description = td.Headsign
+				if td.Line.Color != nil {
+					pm.StyleURL = d.lineStyle(td.Line.Color, defaultWeight)
+				}
+			}
+			if pm != run {
+				flush()
+				run = pm
+			}
+			if len(coords) > 0 && len(ll) > 0 && coords[len(coords)-1] == ll[0] {
+				ll = ll[1:]
+			}
+			coords = append(coords, ll...)
+		}
+		flush()
+		d.doc.Folders = append(d.doc.Folders, f)
+	}
+	return nil
+}
+
+// AddGeocodeResults adds a Placemark for each result, named and described by its FormattedAddress.
+func (d *Document) AddGeocodeResults(rs []maps.GeocodeResult) {
+	s := d.style(style{IconStyle: &iconStyle{Color: kmlColor(defaultMarkerColor), Scale: markerScale(""), Href: defaultIcon}})
+	for _, r := range rs {
+		pm := placemark{
+			Name:        r.FormattedAddress,
+			Description: r.FormattedAddress,
+			StyleURL:    s,
+			Point:       &point{Coordinates: coordinates([]maps.LatLng{r.Geometry.Location})},
+		}
+		d.doc.Placemarks = append(d.doc.Placemarks, pm)
+	}
+}
+
+// AddStaticMap adds the Markers and Paths of the static map to the document, with equivalent KML styles.
+//
+// Markers at locations which are not LatLngs are added with their address, to be geocoded by the KML viewer.
+// Paths must be specified as LatLngs or an encoded polyline.
+func (d *Document) AddStaticMap(opts *maps.StaticMapOpts) error {
+	if opts == nil {
+		return nil
+	}
+	for _, m := range opts.Markers {
+		is := iconStyle{Color: kmlColor(defaultMarkerColor), Scale: markerScale(m.Size), Href: defaultIcon}
+		if m.Color != nil {
+			is.Color = kmlColor(opaque(m.Color))
+		}
+		if m.IconURL != "" {
+			is.Href = m.IconURL
+		}
+		s := d.style(style{IconStyle: &is})
+		for _, l := range m.Locations {
+			pm := placemark{Name: m.Label, StyleURL: s}
+			if ll, ok := latLng(l); ok {
+				pm.Point = &point{Coordinates: coordinates([]maps.LatLng{ll})}
+			} else {
+				pm.Address = l.Location()
+			}
+			d.doc.Placemarks = append(d.doc.Placemarks, pm)
+		}
+	}
+	for _, p := range opts.Paths {
+		ll, err := pathLatLngs(p)
+		if err != nil {
+			return err
+		}
+		ls := lineStyle{Color: kmlColor(defaultRouteColor), Width: defaultWeight}
+		if p.Color != nil {
+			ls.Color = kmlColor(p.Color)
+		}
+		if p.Weight != 0 {
+			ls.Width = p.Weight
+		}
+		s := style{LineStyle: &ls}
+		var tessellate int
+		if p.Geodesic {
+			tessellate = 1
+		}
+		pm := placemark{}
+		if p.FillColor != nil {
+			s.PolyStyle = &polyStyle{Color: kmlColor(p.FillColor)}
+			// KML requires the ring to be closed, by repeating its first location at the end.
+			if len(ll) > 0 && ll[len(ll)-1] != ll[0] {
+				ll = append(ll[:len(ll):len(ll)], ll[0])
+			}
+			pm.Polygon = &polygon{Tessellate: tessellate, Coordinates: coordinates(ll)}
+		} else {
+			pm.LineString = &lineString{Tessellate: tessellate, Coordinates: coordinates(ll)}
+		}
+		pm.StyleURL = d.style(s)
+		d.doc.Placemarks = append(d.doc.Placemarks, pm)
+	}
+	return nil
+}
+
+func pathLatLngs(p maps.Path) ([]maps.LatLng, error) {
+	if p.Polyline != "" {
+		return maps.Polyline{Points: p.Polyline}.Decode()
+	}
+	ll := make([]maps.LatLng, len(p.Locations))
+	for i, l := range p.Locations {
+		var ok bool
+		if ll[i], ok = latLng(l); !ok {
+			return nil, fmt.Errorf("path location %q is not a LatLng", l.Location())
+		}
+	}
+	return ll, nil
+}
+
+// latLng returns the location as a LatLng, if it is a LatLng or *LatLng.
+func latLng(l maps.Location) (maps.LatLng, bool) {
+	switch l := l.(type) {
+	case maps.LatLng:
+		return l, true
+	case *maps.LatLng:
+		return *l, true
+	}
+	return maps.LatLng{}, false
+}
+
+// markerScale returns the icon scale corresponding to a marker size, which is mid-sized if unset, as in the Static Maps API.
+func markerScale(size string) float64 {
+	switch size {
+	case maps.SizeTiny:
+		return 0.5
+	case "small":
+		return 0.65
+	case maps.SizeLarge:
+		return 1
+	}
+	return 0.8
+}
+
+func coordinates(ll []maps.LatLng) string {
+	s := make([]string, len(ll))
+	for i, l := range ll {
+		s[i] = strconv.FormatFloat(l.Lng, 'f', -1, 64) + "," + strconv.FormatFloat(l.Lat, 'f', -1, 64)
+	}
+	return strings.Join(s, " ")
+}
+
+// kmlColor returns the color in KML's aabbggrr format, using the same components as are requested from the Static Maps API.
+func kmlColor(c color.Color) string {
+	r, g, b, a := c.RGBA()
+	return fmt.Sprintf("%02x%02x%02x%02x", a>>8, b>>8, g>>8, r>>8)
+}
+
+// opaque returns the color with its alpha value ignored, as the Static Maps API does for markers.
+func opaque(c color.Color) color.Color {
+	r, g, b, _ := c.RGBA()
+	return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 0xFF}
+}
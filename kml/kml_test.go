@@ -0,0 +1,188 @@
+package kml
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/color"
+	"strings"
+	"testing"
+
+	maps "github.com/imjasonh/staticmaps"
+)
+
+const routeJSON = `{
+  "summary": "Walk and ride",
+  "legs": [{
+    "start_address": "Home",
+    "end_address": "Work",
+    "steps": [{
+      "travel_mode": "WALKING",
+      "polyline": {"points": "_p~iF~ps|U_ulLnnqC"}
+    }, {
+      "travel_mode": "TRANSIT",
+      "polyline": {"points": "_flwFn` + "`" + `faVwrq@` + "`" + `qoA"},
+      "transit_details": {"headsign": "Downtown", "line": {"short_name": "M7", "color": "#FF0033"}}
+    }]
+  }]
+}`
+
+func encode(t *testing.T, d *Document) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := d.Encode(&buf); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return buf.String()
+}
+
+func contains(t *testing.T, out string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("output does not contain %q:\n%s", w, out)
+		}
+	}
+}
+
+func TestAddRoute(t *testing.T) {
+	var r maps.Route
+	if err := json.Unmarshal([]byte(routeJSON), &r); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDocument("Commute")
+	if err := d.AddRoute(r); err != nil {
+		t.Fatalf("AddRoute: %v", err)
+	}
+	out := encode(t, d)
+	contains(t, out,
+		`<kml xmlns="http://www.opengis.net/kml/2.2">`,
+		`<name>Commute</name>`,
+		`<name>Home to Work</name>`,
+		// The walking step, in the default route style.
+		`<color>bfff0000</color>`,
+		`<coordinates>-120.2,38.5 -120.95,40.7</coordinates>`,
+		// The transit step, in the color of its line.
+		`<name>M7</name>`,
+		`<description>Downtown</description>`,
+		`<color>ff3300ff</color>`,
+		`<coordinates>-120.95,40.7 -121.36249,40.95916</coordinates>`,
+	)
+	if n := strings.Count(out, "<Style "); n != 2 {
+		t.Errorf("got %d styles, want 2", n)
+	}
+}
+
+func TestAddGeocodeResults(t *testing.T) {
+	var rs []maps.GeocodeResult
+	if err := json.Unmarshal([]byte(`[{
+		"formatted_address": "1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA",
+		"address_components": [{"long_name": "1600"}],
+		"geometry": {"location": {"lat": 37.42, "lng": -122.08}}
+	}]`), &rs); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDocument("")
+	d.AddGeocodeResults(rs)
+	contains(t, encode(t, d),
+		`<name>1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA</name>`,
+		`<description>1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA</description>`,
+		`<coordinates>-122.08,37.42</coordinates>`,
+	)
+}
+
+func TestAddStaticMap(t *testing.T) {
+	d := NewDocument("")
+	if err := d.AddStaticMap(&maps.StaticMapOpts{
+		Markers: []maps.Markers{{
+			Size:      maps.SizeTiny,
+			Color:     color.RGBA{0x00, 0xFF, 0x00, 0x80},
+			Label:     "A",
+			Locations: []maps.Location{maps.LatLng{Lat: 1, Lng: 2}, maps.Address("Brooklyn Bridge"), &maps.LatLng{Lat: 3, Lng: 4}},
+		}},
+		Paths: []maps.Path{{
+			Weight:    3,
+			Color:     color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+			FillColor: color.RGBA{0x33, 0x33, 0x00, 0x33},
+			Geodesic:  true,
+			Locations: []maps.Location{maps.LatLng{Lat: 0, Lng: 0}, &maps.LatLng{Lat: 1, Lng: 1}, maps.LatLng{Lat: 0, Lng: 1}},
+		}, {
+			Polyline: "_p~iF~ps|U_ulLnnqC",
+		}},
+	}); err != nil {
+		t.Fatalf("AddStaticMap: %v", err)
+	}
+	contains(t, encode(t, d),
+		// Marker alpha is ignored, as it is by the Static Maps API.
+		`<color>ff00ff00</color>`,
+		`<scale>0.5</scale>`,
+		`<name>A</name>`,
+		`<coordinates>2,1</coordinates>`,
+		`<address>Brooklyn Bridge</address>`,
+		`<coordinates>4,3</coordinates>`,
+		`<width>3</width>`,
+		`<PolyStyle>`,
+		`<color>33003333</color>`,
+		`<tessellate>1</tessellate>`,
+		// The polygon's ring is closed.
+		`<coordinates>0,0 1,1 1,0 0,0</coordinates>`,
+		`<coordinates>-120.2,38.5 -120.95,40.7</coordinates>`,
+	)
+
+	if err := d.AddStaticMap(&maps.StaticMapOpts{Paths: []maps.Path{{Locations: []maps.Location{maps.Address("Brooklyn")}}}}); err == nil {
+		t.Error("expected error for path of addresses")
+	}
+}
+
+func TestStyleDeduplication(t *testing.T) {
+	d := NewDocument("")
+	path := maps.Path{Color: color.RGBA{0xFF, 0x00, 0x00, 0xFF}, Locations: []maps.Location{maps.LatLng{Lat: 0, Lng: 0}, maps.LatLng{Lat: 1, Lng: 1}}}
+	if err := d.AddStaticMap(&maps.StaticMapOpts{Paths: []maps.Path{path, path, path}}); err != nil {
+		t.Fatalf("AddStaticMap: %v", err)
+	}
+	d.AddGeocodeResults([]maps.GeocodeResult{{}})
+	d.AddGeocodeResults([]maps.GeocodeResult{{}})
+	if n := strings.Count(encode(t, d), "<Style "); n != 2 {
+		t.Errorf("got %d styles, want 2", n)
+	}
+
+	// Consecutive steps on the same transit line are drawn as one LineString.
+	var r maps.Route
+	if err := json.Unmarshal([]byte(`{"legs": [{"steps": [
+		{"polyline": {"points": "_p~iF~ps|U_ulLnnqC"}, "transit_details": {"line": {"short_name": "M7", "color": "#FF0033"}}},
+		{"polyline": {"points": "_flwFn`+"`"+`faVwrq@`+"`"+`qoA"}, "transit_details": {"line": {"short_name": "M7", "color": "#FF0033"}}}
+	]}]}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	d = NewDocument("")
+	if err := d.AddRoute(r); err != nil {
+		t.Fatalf("AddRoute: %v", err)
+	}
+	out := encode(t, d)
+	if n := strings.Count(out, "<LineString>"); n != 1 {
+		t.Errorf("got %d LineStrings, want 1:\n%s", n, out)
+	}
+	contains(t, out, `<coordinates>-120.2,38.5 -120.95,40.7 -121.36249,40.95916</coordinates>`)
+}
+
+func TestClosedPolygon(t *testing.T) {
+	d := NewDocument("")
+	if err := d.AddStaticMap(&maps.StaticMapOpts{Paths: []maps.Path{{
+		FillColor: color.Black,
+		Locations: []maps.Location{maps.LatLng{Lat: 0, Lng: 0}, maps.LatLng{Lat: 1, Lng: 1}, maps.LatLng{Lat: 0, Lng: 1}, maps.LatLng{Lat: 0, Lng: 0}},
+	}}}); err != nil {
+		t.Fatalf("AddStaticMap: %v", err)
+	}
+	// A ring which is already closed is not closed again.
+	contains(t, encode(t, d), `<coordinates>0,0 1,1 1,0 0,0</coordinates>`)
+}
+
+func TestMarkerScale(t *testing.T) {
+	for _, c := range []struct {
+		size string
+		exp  float64
+	}{{"", 0.8}, {maps.SizeTiny, 0.5}, {"small", 0.65}, {maps.SizeMid, 0.8}, {maps.SizeLarge, 1}} {
+		if got := markerScale(c.size); got != c.exp {
+			t.Errorf("markerScale(%q): got %v, want %v", c.size, got, c.exp)
+		}
+	}
+}
@@ -1,13 +1,18 @@
 package maps
 
 import (
+	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -34,75 +39,305 @@ const (
 	StatusOverQueryLimit = "OVER_QUERY_LIMIT"
 )
 
-func do(ctx context.Context, url string) (*http.Response, error) {
-	cl := httpClient(ctx)
-	req, err := http.NewRequest("GET", url, nil)
+// Service identifies a family of Google Maps web service endpoints.
+type Service string
+
+const (
+	// ServiceGeocode identifies the Geocode and ReverseGeocode endpoints.
+	ServiceGeocode Service = "geocode"
+	// ServiceDirections identifies the Directions endpoint.
+	ServiceDirections Service = "directions"
+	// ServiceDistanceMatrix identifies the DistanceMatrix endpoint.
+	ServiceDistanceMatrix Service = "distancematrix"
+	// ServiceElevation identifies the Elevation endpoints.
+	ServiceElevation Service = "elevation"
+	// ServiceTimeZone identifies the TimeZone endpoint.
+	ServiceTimeZone Service = "timezone"
+	// ServiceRoads identifies the Roads API endpoints, such as SnapToRoads.
+	ServiceRoads Service = "roads"
+	// ServiceStaticMap identifies the StaticMap endpoint.
+	ServiceStaticMap Service = "staticmap"
+	// ServiceStreetView identifies the StreetView endpoint.
+	ServiceStreetView Service = "streetview"
+)
+
+// do requests the path, relative to the base URL of the given Service.
+func (c *Client) do(ctx context.Context, svc Service, path string) (*http.Response, error) {
+	u, err := c.requestURL(svc, path)
 	if err != nil {
 		return nil, err
 	}
-	q := req.URL.Query()
-	if k := key(ctx); k != "" {
-		q.Set("key", k)
-	}
-	clientID, privKey := workCreds(ctx)
-	if clientID != "" {
-		q.Set("client", clientID)
-	}
-	enc := q.Encode()
-	if privKey != "" {
-		sig, err := genSig(privKey, req.URL.Path, enc)
-		if err != nil {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx, svc); err != nil {
 			return nil, err
 		}
-		enc += "&signature=" + sig
 	}
-	req.URL.RawQuery = enc
-	return cl.Do(req)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return c.client().Do(req)
 }
 
-// See https://developers.google.com/maps/documentation/business/webservices/auth
-func genSig(privKey, path, query string) (string, error) {
-	toSign := path + "?" + query
-	decodedKey, err := base64.URLEncoding.DecodeString(privKey)
+// requestURL returns the signed URL of the path, relative to the base URL of the given Service, as a string.
+//
+// For the StaticMap and StreetView services, a URLTooLongError is returned if the URL is longer than MaxURLLength.
+// Other services accept longer URLs, so their length is left for the API server to check.
+func (c *Client) requestURL(svc Service, path string) (string, error) {
+	u, err := c.signedURL(svc, path)
 	if err != nil {
 		return "", err
 	}
-	d := hmac.New(sha1.New, decodedKey)
-	if _, err := d.Write([]byte(toSign)); err != nil {
-		return "", err
+	s := u.String()
+	if (svc == ServiceStaticMap || svc == ServiceStreetView) && len(s) > MaxURLLength {
+		return "", URLTooLongError{Length: len(s), Max: MaxURLLength}
+	}
+	return s, nil
+}
+
+// signedURL returns the URL of the path, relative to the base URL of the given Service, including the Client's credentials and signature.
+func (c *Client) signedURL(svc Service, path string) (*url.URL, error) {
+	u, err := url.Parse(c.serviceBaseURL(svc) + path)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	if c.key != "" {
+		q.Set("key", c.key)
+	}
+	if c.clientID != "" {
+		q.Set("client", c.clientID)
+	}
+	if c.channel != "" {
+		q.Set("channel", c.channel)
+	}
+	enc := q.Encode()
+	switch {
+	case c.workKeyErr != nil:
+		return nil, c.workKeyErr
+	case c.workKey != nil:
+		enc += "&signature=" + sign(c.workKey, u.Path, enc)
+	case c.secret != nil && (svc == ServiceStaticMap || svc == ServiceStreetView):
+		enc += "&signature=" + sign(c.secret, u.Path, enc)
 	}
-	return base64.URLEncoding.EncodeToString(d.Sum(nil)), nil
+	u.RawQuery = enc
+	return u, nil
 }
 
-func doDecode(ctx context.Context, url string, r interface{}) error {
-	resp, err := do(ctx, url)
+// decodeKey decodes a private key or URL signing secret.
+func decodeKey(key string) ([]byte, error) {
+	return base64.URLEncoding.DecodeString(key)
+}
+
+// sign returns the signature of the path and query, using the decoded private key or URL signing secret.
+//
+// See https://developers.google.com/maps/documentation/business/webservices/auth
+func sign(key []byte, path, query string) string {
+	d := hmac.New(sha1.New, key)
+	d.Write([]byte(path + "?" + query))
+	return base64.URLEncoding.EncodeToString(d.Sum(nil))
+}
+
+// apiResponse is implemented by JSON responses which report the status of the request in their body.
+type apiResponse interface {
+	apiStatus() (status, message string)
+}
+
+// doDecode requests the path, relative to the base URL of the given Service, and decodes the JSON response into r.
+//
+// If r reports a status other than StatusOK, an APIError is returned. Statuses which may succeed if
+// retried, such as StatusOverQueryLimit, are retried according to the Client's RetryPolicy.
+func (c *Client) doDecode(ctx context.Context, svc Service, path string, r interface{}) error {
+	key := c.serviceBaseURL(svc) + path
+	if b, ok := c.cache.get(svc, key); ok {
+		// Only successful responses are cached.
+		return json.Unmarshal(b, r)
+	}
+	p := c.retryPolicy()
+	for attempt := 1; ; attempt++ {
+		resp, err := c.do(ctx, svc, path)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return newHTTPError(resp)
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, r); err != nil {
+			return err
+		}
+		ar, ok := r.(apiResponse)
+		if !ok {
+			c.cache.set(svc, key, b)
+			return nil
+		}
+		status, msg := ar.apiStatus()
+		if status == StatusOK {
+			c.cache.set(svc, key, b)
+			return nil
+		}
+		apiErr := APIError{Status: status, Message: msg, Attempts: attempt}
+		wait, retry := p.Backoff(attempt, nil, apiErr)
+		if !retry {
+			return apiErr
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return RetryCanceledError{Attempts: attempt, Err: err, LastErr: apiErr}
+		}
+	}
+}
+
+// doImage requests the path, relative to the base URL of the given Service, and returns the body of the response.
+func (c *Client) doImage(ctx context.Context, svc Service, path string) (io.ReadCloser, error) {
+	key := c.serviceBaseURL(svc) + path
+	if b, ok := c.cache.get(svc, key); ok {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+	resp, err := c.do(ctx, svc, path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return HTTPError{resp}
+		return nil, newHTTPError(resp)
+	}
+	if c.cache == nil {
+		return resp.Body, nil
 	}
 	defer resp.Body.Close()
-	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
-		return err
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	c.cache.set(svc, key, b)
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
 }
 
-// HTTPError indicates an error communicating with the API server, and includes the HTTP response returned from the server.
+// MaxURLLength is the maximum length of a StaticMap or StreetView request URL accepted by the API server, including credentials and signature.
+const MaxURLLength = 8192
+
+// URLTooLongError indicates that a StaticMap or StreetView request URL was not sent because it is longer than the API server accepts.
+type URLTooLongError struct {
+	// Length is the length of the request URL.
+	Length int
+
+	// Max is the maximum length of a request URL.
+	Max int
+}
+
+func (e URLTooLongError) Error() string {
+	return fmt.Sprintf("request URL is %d characters, longer than the maximum of %d", e.Length, e.Max)
+}
+
+// Errors corresponding to each failure status reported by the API server.
+//
+// These can be used with errors.Is to determine the status of an APIError, e.g., errors.Is(err, ErrZeroResults).
+var (
+	ErrNotFound             = errors.New(StatusNotFound)
+	ErrZeroResults          = errors.New(StatusZeroResults)
+	ErrMaxWaypointsExceeded = errors.New(StatusMaxWaypointsExceeded)
+	ErrInvalidRequest       = errors.New(StatusInvalidRequest)
+	ErrRequestDenied        = errors.New(StatusRequestDenied)
+	ErrUnknownError         = errors.New(StatusUnknownError)
+	ErrOverQueryLimit       = errors.New(StatusOverQueryLimit)
+)
+
+var statusErrors = map[string]error{
+	StatusNotFound:             ErrNotFound,
+	StatusZeroResults:          ErrZeroResults,
+	StatusMaxWaypointsExceeded: ErrMaxWaypointsExceeded,
+	StatusInvalidRequest:       ErrInvalidRequest,
+	StatusRequestDenied:        ErrRequestDenied,
+	StatusUnknownError:         ErrUnknownError,
+	StatusOverQueryLimit:       ErrOverQueryLimit,
+}
+
+// maxErrorBody is the maximum number of bytes of a failed response's body included in an HTTPError.
+const maxErrorBody = 1024
+
+// HTTPError indicates an error communicating with the API server, and includes the HTTP status code and the beginning of the body returned from the server.
 type HTTPError struct {
-	// Response is the http.Response returned from the API request.
-	Response *http.Response
+	// StatusCode is the HTTP status code returned from the API request.
+	StatusCode int
+
+	// Body contains up to the first 1024 bytes of the body returned from the API request, which may describe the error.
+	Body string
+}
+
+// newHTTPError returns an HTTPError describing resp, and closes its body.
+func newHTTPError(resp *http.Response) HTTPError {
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	io.Copy(ioutil.Discard, resp.Body)
+	return HTTPError{StatusCode: resp.StatusCode, Body: string(b)}
 }
 
 func (e HTTPError) Error() string {
-	return fmt.Sprintf("http error %d", e.Response.StatusCode)
+	if e.Body != "" {
+		return fmt.Sprintf("http error %d: %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("http error %d", e.StatusCode)
+}
+
+// Retryable reports whether the request may succeed if it is retried, i.e., whether the server reported a server error or asked the client to slow down.
+func (e HTTPError) Retryable() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+}
+
+// RetryCanceledError indicates that a request was abandoned because its context was done while waiting to retry a failed attempt.
+//
+// It is distinguished from errors communicating with the API server, which are returned as-is.
+type RetryCanceledError struct {
+	// Attempts is the number of attempts that were made before the request was abandoned.
+	Attempts int
+
+	// Err is the error reported by the context, either context.Canceled or context.DeadlineExceeded.
+	Err error
+
+	// LastErr is the error returned by the last failed attempt.
+	LastErr error
+}
+
+func (e RetryCanceledError) Error() string {
+	return fmt.Sprintf("%v while waiting to retry after %d attempts, last error: %v", e.Err, e.Attempts, e.LastErr)
+}
+
+// Unwrap returns the error reported by the context, so that errors.Is(err, context.Canceled) reports whether the request was cancelled.
+func (e RetryCanceledError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the request may succeed if it is retried, which is never the case once its context is done.
+func (e RetryCanceledError) Retryable() bool {
+	return false
 }
 
 // APIError indicates a failure response from the API server, even though a successful HTTP response was returned.
 // Its Status and Message fields can be consulted for more information about the specific error conditions.
 type APIError struct {
 	Status, Message string
+
+	// Attempts is the number of attempts that were made before the error was returned.
+	//
+	// Requests which fail with StatusOverQueryLimit or StatusUnknownError are retried according to the Client's RetryPolicy.
+	Attempts int
+}
+
+// Retryable reports whether the request may succeed if it is retried, i.e., whether its Status is StatusOverQueryLimit or StatusUnknownError.
+func (e APIError) Retryable() bool {
+	return e.Status == StatusOverQueryLimit || e.Status == StatusUnknownError
+}
+
+// Is reports whether target is the error corresponding to e's Status, such as ErrZeroResults.
+func (e APIError) Is(target error) bool {
+	err, ok := statusErrors[e.Status]
+	return ok && err == target
 }
 
 func (e APIError) Error() string {
@@ -148,6 +383,35 @@ func encodeLocations(ls []Location) string {
 	return strings.Join(s, "|")
 }
 
+// encodePath encodes ls for a path or locations parameter.
+//
+// If all of the locations are LatLngs, they are encoded as a polyline if that is shorter once escaped in a URL,
+// which is usually the case. Polylines are encoded with 5 digits of precision, rather than 6.
+func encodePath(ls []Location) string {
+	ll := make([]LatLng, len(ls))
+	for i, l := range ls {
+		switch l := l.(type) {
+		case LatLng:
+			ll[i] = l
+		case *LatLng:
+			ll[i] = *l
+		default:
+			return encodeLocations(ls)
+		}
+	}
+	return encodeLatLngPath(ll)
+}
+
+// encodeLatLngPath encodes ll for a path or locations parameter, as a polyline if that is shorter once escaped in a URL.
+func encodeLatLngPath(ll []LatLng) string {
+	plain := encodeLatLngs(ll)
+	enc := "enc:" + EncodePolyline(ll).Points
+	if len(ll) > 0 && len(url.QueryEscape(enc)) < len(url.QueryEscape(plain)) {
+		return enc
+	}
+	return plain
+}
+
 func encodeLatLngs(ll []LatLng) string {
 	s := make([]string, len(ll))
 	for i, l := range ll {
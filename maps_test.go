@@ -1,27 +1,61 @@
 package maps
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"image/color"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/imjasonh/staticmaps/mapstest"
 )
 
 var (
 	apiKey string
 	ctx    context.Context
+	fake   *mapstest.Server
 	wctx   = NewWorkContext("clientID", "privKey", &http.Client{})
 )
 
-func init() {
+// TestMain runs tests against the live API if API_KEY is provided, and against a fake server otherwise.
+func TestMain(m *testing.M) {
 	apiKey = os.Getenv("API_KEY")
-	if apiKey == "" {
-		log.Fatal("API_KEY must be provided")
+	if apiKey != "" {
+		ctx = NewContext(apiKey, http.DefaultClient)
+		os.Exit(m.Run())
+	}
+
+	apiKey = "fake"
+	fake = mapstest.NewServer()
+	fake.Key = apiKey
+	c, err := NewClient(WithAPIKey(apiKey), WithHTTPClient(http.DefaultClient),
+		WithBaseURL(fake.MapsURL()), WithServiceBaseURL(ServiceRoads, fake.RoadsURL()))
+	if err != nil {
+		log.Fatal(err)
 	}
-	ctx = NewContext(apiKey, http.DefaultClient)
+	ctx = WithClient(context.Background(), c)
+	code := m.Run()
+	fake.Close()
+	os.Exit(code)
+}
+
+// fakeClient returns a Client which sends requests to the fake server, skipping the test if running against the live API.
+func fakeClient(t *testing.T, opts ...ClientOption) *Client {
+	if fake == nil {
+		t.Skip("not running against fake server")
+	}
+	fake.Reset()
+	c, err := NewClient(append([]ClientOption{WithAPIKey(apiKey), WithBaseURL(fake.MapsURL()), WithServiceBaseURL(ServiceRoads, fake.RoadsURL())}, opts...)...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return c
 }
 
 func TestDirections(t *testing.T) {
@@ -83,7 +117,7 @@ func TestStaticMap(t *testing.T) {
 		}},
 	}
 	t.Logf("%s", baseURL+staticmap(s, opts))
-	ctx := NewContext(apiKey, &http.Client{})
+	ctx := WithContext(ctx, apiKey, &http.Client{})
 	if _, err := StaticMap(ctx, s, opts); err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -207,17 +241,231 @@ func TestSnapToRoads(t *testing.T) {
 func TestSignature(t *testing.T) {
 	clientID := "clientID"
 	privateKey := "vNIXE0xscrmjlyV-12Nj_BvUPaw="
-	sig, err := genSig(privateKey, "/maps/api/geocode/json", "address=New+York&client="+clientID)
+	key, err := decodeKey(privateKey)
 	if err != nil {
-		t.Errorf("unexpected error: %v", err)
+		t.Fatalf("unexpected error: %v", err)
 	}
+	sig := sign(key, "/maps/api/geocode/json", "address=New+York&client="+clientID)
 	exp := "chaRF2hTJKOScPr-RQCEhZbSzIE="
 	if sig != exp {
 		t.Errorf("unexpected signature, got %q, want %q", sig, exp)
 	}
 
 	ctx := NewWorkContext(clientID, privateKey, nil)
-	if gcid, gkey := workCreds(ctx); gcid != clientID || gkey != privateKey {
-		t.Errorf("unepxected credentials from context, got %q and %q, want %q and %q", gcid, gkey, clientID, privateKey)
+	if c := fromContext(ctx); c.clientID != clientID || !bytes.Equal(c.workKey, key) {
+		t.Errorf("unexpected credentials from context, got %q and %q, want %q and %q", c.clientID, c.workKey, clientID, key)
+	}
+}
+
+func TestContextPreservesCredentials(t *testing.T) {
+	clientID, privateKey := "clientID", "vNIXE0xscrmjlyV-12Nj_BvUPaw="
+	ctx := WithContext(NewWorkContext(clientID, privateKey, nil), "key", nil)
+	key, _ := decodeKey(privateKey)
+	if c := fromContext(ctx); c.clientID != clientID || !bytes.Equal(c.workKey, key) {
+		t.Errorf("unexpected credentials from context, got %q and %q, want %q and %q", c.clientID, c.workKey, clientID, key)
+	}
+	if k := fromContext(ctx).key; k != "key" {
+		t.Errorf("unexpected key from context, got %q, want %q", k, "key")
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	c, err := NewClient(WithAPIKey("key"), WithBaseURL("http://localhost:8080/maps/api"), WithChannel("channel"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.key != "key" {
+		t.Errorf("unexpected key, got %q, want %q", c.key, "key")
+	}
+	if exp := "http://localhost:8080/maps/api/"; c.baseURL != exp {
+		t.Errorf("unexpected base URL, got %q, want %q", c.baseURL, exp)
+	}
+
+	if _, err := NewClient(WithBaseURL("/maps/api/")); err == nil {
+		t.Errorf("expected error for relative base URL")
+	}
+}
+
+func TestRetryAPIStatus(t *testing.T) {
+	c := fakeClient(t, WithRetryPolicy(ExponentialBackoff{BaseDelay: time.Millisecond}))
+	over := mapstest.Status(StatusOverQueryLimit)
+	fake.Enqueue(mapstest.Geocode, over, over)
+	if _, err := c.Geocode(context.Background(), &GeocodeOpts{Address: "New York"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if n := len(fake.Requests(mapstest.Geocode)); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+
+	fake.Enqueue(mapstest.Geocode, over, over, over, over, over)
+	_, err := c.Geocode(context.Background(), &GeocodeOpts{Address: "New York"})
+	var apiErr APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got error %v, want APIError", err)
+	}
+	if apiErr.Status != StatusOverQueryLimit || apiErr.Attempts != 5 {
+		t.Errorf("got status %q after %d attempts, want %q after 5 attempts", apiErr.Status, apiErr.Attempts, StatusOverQueryLimit)
+	}
+
+	fake.Enqueue(mapstest.Geocode, mapstest.Status(StatusZeroResults))
+	if _, err := c.Geocode(context.Background(), &GeocodeOpts{Address: "Nowhere"}); !errors.As(err, &apiErr) || apiErr.Attempts != 1 {
+		t.Errorf("got error %v, want APIError after 1 attempt", err)
+	}
+}
+
+func TestServiceBaseURL(t *testing.T) {
+	c := fakeClient(t)
+	if _, err := c.SnapToRoads(context.Background(), []LatLng{{1, 2}}, &SnapToRoadsOpts{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	r, ok := fake.LastRequest(mapstest.SnapToRoads)
+	if !ok {
+		t.Fatalf("no request for %s", mapstest.SnapToRoads)
+	}
+	if got, exp := r.Query.Get("path"), "1.000000,2.000000"; got != exp {
+		t.Errorf("got path %q, want %q", got, exp)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	c := fakeClient(t)
+	fake.Enqueue(mapstest.Elevation, mapstest.StatusMessage(StatusInvalidRequest, "Invalid request. Missing the 'locations' parameter."))
+	_, err := c.Elevation(context.Background(), nil)
+	if !errors.Is(err, ErrInvalidRequest) || errors.Is(err, ErrZeroResults) {
+		t.Errorf("got error %v, want %v", err, ErrInvalidRequest)
+	}
+	var apiErr APIError
+	if !errors.As(err, &apiErr) || apiErr.Message == "" || apiErr.Retryable() {
+		t.Errorf("got error %#v, want non-retryable APIError with message", err)
+	}
+
+	fake.Enqueue(mapstest.SnapToRoads, mapstest.HTTPStatus(http.StatusBadRequest))
+	_, err = c.SnapToRoads(context.Background(), nil, &SnapToRoadsOpts{})
+	var httpErr HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("got error %v, want HTTPError", err)
+	}
+	if httpErr.StatusCode != http.StatusBadRequest || httpErr.Body != http.StatusText(http.StatusBadRequest) || httpErr.Retryable() {
+		t.Errorf("got error %#v, want non-retryable HTTPError with status %d and body", httpErr, http.StatusBadRequest)
+	}
+}
+
+func TestEncodePath(t *testing.T) {
+	ll := []LatLng{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}}
+	if got, exp := encodeLatLngPath(ll), "enc:_p~iF~ps|U_ulLnnqC_mqNvxq`@"; got != exp {
+		t.Errorf("got %q, want %q", got, exp)
+	}
+	ls := []Location{ll[0], &ll[1], ll[2]}
+	if got, exp := (Path{Weight: 5, Locations: ls}).encode(), "weight:5|enc:_p~iF~ps|U_ulLnnqC_mqNvxq`@"; got != exp {
+		t.Errorf("got %q, want %q", got, exp)
+	}
+	ls = []Location{ll[0], Address("New York")}
+	if got, exp := (Path{Locations: ls}).encode(), "38.500000,-120.200000|New York"; got != exp {
+		t.Errorf("got %q, want %q", got, exp)
+	}
+
+	c := fakeClient(t)
+	if _, err := c.ElevationPath(context.Background(), ll, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r, _ := fake.LastRequest(mapstest.Elevation); r.Query.Get("path") != "enc:_p~iF~ps|U_ulLnnqC_mqNvxq`@" {
+		t.Errorf("got path %q, want encoded polyline", r.Query.Get("path"))
+	}
+}
+
+func TestStaticMapURL(t *testing.T) {
+	privateKey := "vNIXE0xscrmjlyV-12Nj_BvUPaw="
+	c, err := NewClient(WithClientID("clientID", privateKey))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := Size{400, 400}
+	opts := &StaticMapOpts{Center: Address("New York"), Zoom: 12}
+	got, err := c.StaticMapURL(s, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u.Host != "maps.googleapis.com" || u.Path != "/maps/api/staticmap" {
+		t.Errorf("unexpected URL %q", got)
+	}
+	q := strings.SplitN(u.RawQuery, "&signature=", 2)
+	if len(q) != 2 {
+		t.Fatalf("URL %q is not signed", got)
+	}
+	key, _ := decodeKey(privateKey)
+	if exp := sign(key, u.Path, q[0]); q[1] != exp {
+		t.Errorf("got signature %q, want %q", q[1], exp)
+	}
+	if u.Query().Get("client") != "clientID" || u.Query().Get("center") != "New York" {
+		t.Errorf("unexpected query in URL %q", got)
+	}
+
+	ctx := NewContext("key", nil)
+	got, err = StreetViewURL(ctx, s, &StreetViewOpts{Location: Address("New York")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := "https://maps.googleapis.com/maps/api/streetview?key=key&location=New+York&size=400x400"; got != exp {
+		t.Errorf("got %q, want %q", got, exp)
+	}
+
+	// Only StaticMap and StreetView URLs are limited to MaxURLLength.
+	long := "?address=" + strings.Repeat("a", MaxURLLength)
+	var tooLong URLTooLongError
+	for _, svc := range []Service{ServiceStaticMap, ServiceStreetView} {
+		if _, err := c.requestURL(svc, string(svc)+long); !errors.As(err, &tooLong) {
+			t.Errorf("%s: got error %v, want URLTooLongError", svc, err)
+		}
+	}
+	for _, svc := range []Service{ServiceGeocode, ServiceDirections, ServiceElevation} {
+		if _, err := c.requestURL(svc, string(svc)+"/json"+long); err != nil {
+			t.Errorf("%s: unexpected error: %v", svc, err)
+		}
+	}
+}
+
+func TestSigningSecret(t *testing.T) {
+	secret := "vNIXE0xscrmjlyV-12Nj_BvUPaw="
+	c := fakeClient(t, WithSigningSecret(secret))
+	s := Size{400, 400}
+	got, err := c.StaticMapURL(s, &StaticMapOpts{Center: Address("New York")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	u, _ := url.Parse(got)
+	q := strings.SplitN(u.RawQuery, "&signature=", 2)
+	if len(q) != 2 || u.Query().Get("key") != apiKey {
+		t.Fatalf("URL %q is not signed with API key", got)
+	}
+	key, _ := decodeKey(secret)
+	if exp := sign(key, u.Path, q[0]); q[1] != exp {
+		t.Errorf("got signature %q, want %q", q[1], exp)
+	}
+
+	if _, err := c.StreetView(context.Background(), s, &StreetViewOpts{Location: Address("New York")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r, _ := fake.LastRequest(mapstest.StreetView); r.Query.Get("signature") == "" {
+		t.Errorf("StreetView request was not signed")
+	}
+	if _, err := c.Geocode(context.Background(), &GeocodeOpts{Address: "New York"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r, _ := fake.LastRequest(mapstest.Geocode); r.Query.Get("signature") != "" {
+		t.Errorf("Geocode request was unexpectedly signed")
+	}
+
+	if _, err := NewClient(WithAPIKey("key"), WithSigningSecret("not base64!")); err == nil {
+		t.Errorf("expected error for malformed signing secret")
+	}
+	if _, err := NewClient(WithClientID("clientID", "not base64!")); err == nil {
+		t.Errorf("expected error for malformed private key")
+	}
+	if _, err := StaticMapURL(wctx, s, nil); err == nil {
+		t.Errorf("expected error for malformed private key in context")
 	}
 }
@@ -0,0 +1,225 @@
+// Package mapstest provides an in-process fake of the Google Maps web services, for use in tests.
+//
+// A Server responds to requests for each endpoint with a minimal successful response by default.
+// Tests can script canned responses, inject failures, and inspect the requests that were made:
+//
+//	s := mapstest.NewServer()
+//	defer s.Close()
+//	s.Enqueue(mapstest.Geocode, mapstest.Status("OVER_QUERY_LIMIT"), mapstest.JSON(resp))
+//	c, err := maps.NewClient(maps.WithAPIKey("key"), maps.WithBaseURL(s.MapsURL()), maps.WithServiceBaseURL(maps.ServiceRoads, s.RoadsURL()))
+package mapstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+)
+
+// Endpoints served by a Server, identified by their path.
+const (
+	Directions     = "/maps/api/directions/json"
+	DistanceMatrix = "/maps/api/distancematrix/json"
+	Elevation      = "/maps/api/elevation/json"
+	Geocode        = "/maps/api/geocode/json"
+	TimeZone       = "/maps/api/timezone/json"
+	StaticMap      = "/maps/api/staticmap"
+	StreetView     = "/maps/api/streetview"
+	SnapToRoads    = "/v1/snapToRoads"
+)
+
+// Response describes a canned response to a request.
+type Response struct {
+	// StatusCode is the HTTP status code of the response. If zero, http.StatusOK is used.
+	StatusCode int
+
+	// ContentType is the value of the Content-Type header of the response.
+	ContentType string
+
+	// Body is the body of the response.
+	Body []byte
+}
+
+// JSON returns a Response whose body is v encoded as JSON.
+//
+// It panics if v cannot be encoded.
+func JSON(v interface{}) Response {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return Response{ContentType: "application/json", Body: b}
+}
+
+// Status returns a Response which reports the given API status, such as "ZERO_RESULTS" or "OVER_QUERY_LIMIT".
+func Status(status string) Response {
+	return JSON(map[string]string{"status": status})
+}
+
+// StatusMessage returns a Response which reports the given API status and error message.
+func StatusMessage(status, message string) Response {
+	return JSON(map[string]string{"status": status, "error_message": message})
+}
+
+// HTTPStatus returns a Response with the given HTTP status code, such as http.StatusInternalServerError.
+func HTTPStatus(code int) Response {
+	return Response{StatusCode: code, ContentType: "text/plain", Body: []byte(http.StatusText(code))}
+}
+
+// Image returns a Response containing the given image encoded as PNG.
+//
+// It panics if the image cannot be encoded.
+func Image(img image.Image) Response {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		panic(err)
+	}
+	return Response{ContentType: "image/png", Body: buf.Bytes()}
+}
+
+// Request describes a request received by a Server.
+type Request struct {
+	// Endpoint is the path of the request, e.g., Geocode.
+	Endpoint string
+
+	// Query contains the query parameters of the request.
+	Query url.Values
+
+	// Header contains the headers of the request.
+	Header http.Header
+}
+
+// Server is a fake Google Maps web services server.
+//
+// Its methods are safe for concurrent use by multiple goroutines.
+type Server struct {
+	// URL is the base URL of the server, of the form http://ipaddr:port with no trailing slash.
+	URL string
+
+	// Key, if set, is the API key that requests must include. Requests with any other key, and
+	// without a Google Maps API for Work client ID, fail with the status "REQUEST_DENIED".
+	Key string
+
+	srv      *httptest.Server
+	mu       sync.Mutex
+	queued   map[string][]Response
+	defaults map[string]Response
+	requests []Request
+}
+
+// NewServer starts and returns a new Server. The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		queued: map[string][]Response{},
+		defaults: map[string]Response{
+			Directions:     JSON(map[string]interface{}{"status": "OK", "routes": []interface{}{}}),
+			DistanceMatrix: JSON(map[string]interface{}{"status": "OK", "rows": []interface{}{}}),
+			Elevation:      JSON(map[string]interface{}{"status": "OK", "results": []interface{}{}}),
+			Geocode:        JSON(map[string]interface{}{"status": "OK", "results": []interface{}{}}),
+			TimeZone:       JSON(map[string]interface{}{"status": "OK"}),
+			StaticMap:      Image(image.NewRGBA(image.Rect(0, 0, 1, 1))),
+			StreetView:     Image(image.NewRGBA(image.Rect(0, 0, 1, 1))),
+			SnapToRoads:    JSON(map[string]interface{}{"snappedPoints": []interface{}{}}),
+		},
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// MapsURL returns the base URL to use for Maps web services, suitable for maps.WithBaseURL.
+func (s *Server) MapsURL() string {
+	return s.URL + "/maps/api/"
+}
+
+// RoadsURL returns the base URL to use for the Roads API, suitable for maps.WithServiceBaseURL.
+func (s *Server) RoadsURL() string {
+	return s.URL + "/v1/"
+}
+
+// Enqueue adds responses to be served, in order, to requests for the given endpoint.
+//
+// Once all enqueued responses have been served, the endpoint's default response is served.
+func (s *Server) Enqueue(endpoint string, rs ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued[endpoint] = append(s.queued[endpoint], rs...)
+}
+
+// SetDefault sets the response to serve to requests for the given endpoint when no responses are enqueued.
+func (s *Server) SetDefault(endpoint string, r Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaults[endpoint] = r
+}
+
+// Requests returns the requests received for the given endpoint, in the order they were received.
+//
+// If endpoint is empty, requests for all endpoints are returned.
+func (s *Server) Requests(endpoint string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rs []Request
+	for _, r := range s.requests {
+		if endpoint == "" || r.Endpoint == endpoint {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// LastRequest returns the most recent request received for the given endpoint, and whether any request was received.
+func (s *Server) LastRequest(endpoint string) (Request, bool) {
+	rs := s.Requests(endpoint)
+	if len(rs) == 0 {
+		return Request{}, false
+	}
+	return rs[len(rs)-1], true
+}
+
+// Reset discards all enqueued responses and recorded requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued = map[string][]Response{}
+	s.requests = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Endpoint: r.URL.Path, Query: q, Header: r.Header.Clone()})
+	resp, ok := s.defaults[r.URL.Path]
+	rs := s.queued[r.URL.Path]
+	switch {
+	case !ok && len(rs) == 0:
+		resp = HTTPStatus(http.StatusNotFound)
+	case s.Key != "" && q.Get("key") != s.Key && q.Get("client") == "":
+		// Denied requests don't consume queued responses, so that later requests receive them as scripted.
+		resp = StatusMessage("REQUEST_DENIED", "The provided API key is invalid.")
+		if r.URL.Path == SnapToRoads {
+			resp.StatusCode = http.StatusForbidden
+		}
+	case len(rs) > 0:
+		resp = rs[0]
+		s.queued[r.URL.Path] = rs[1:]
+	}
+	s.mu.Unlock()
+
+	if resp.ContentType != "" {
+		w.Header().Set("Content-Type", resp.ContentType)
+	}
+	if resp.StatusCode != 0 {
+		w.WriteHeader(resp.StatusCode)
+	}
+	w.Write(resp.Body)
+}
@@ -0,0 +1,66 @@
+package mapstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func get(t *testing.T, url string) (int, map[string]interface{}) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	return resp.StatusCode, body
+}
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Key = "key"
+	s.Enqueue(Geocode, Status("ZERO_RESULTS"), HTTPStatus(http.StatusInternalServerError))
+
+	for _, c := range []struct {
+		code   int
+		status interface{}
+	}{
+		{http.StatusOK, "ZERO_RESULTS"},
+		{http.StatusInternalServerError, nil},
+		{http.StatusOK, "OK"},
+	} {
+		code, body := get(t, s.MapsURL()+"geocode/json?address=NYC&key=key")
+		if code != c.code || body["status"] != c.status {
+			t.Errorf("got %d %v, want %d %v", code, body["status"], c.code, c.status)
+		}
+	}
+
+	if _, body := get(t, s.MapsURL()+"geocode/json?address=NYC&key=wrong"); body["status"] != "REQUEST_DENIED" {
+		t.Errorf("got status %v for wrong key, want REQUEST_DENIED", body["status"])
+	}
+	if code, _ := get(t, s.RoadsURL()+"snapToRoads?key=wrong"); code != http.StatusForbidden {
+		t.Errorf("got %d for wrong key, want %d", code, http.StatusForbidden)
+	}
+
+	rs := s.Requests(Geocode)
+	if len(rs) != 4 {
+		t.Fatalf("got %d requests, want 4", len(rs))
+	}
+	if got := rs[0].Query.Get("address"); got != "NYC" {
+		t.Errorf("got address %q, want %q", got, "NYC")
+	}
+	if r, ok := s.LastRequest(SnapToRoads); !ok || r.Query.Get("key") != "wrong" {
+		t.Errorf("unexpected last request %v", r)
+	}
+
+	// A denied request doesn't consume the next queued response.
+	s.Enqueue(Geocode, Status("ZERO_RESULTS"))
+	if _, body := get(t, s.MapsURL()+"geocode/json?address=NYC&key=wrong"); body["status"] != "REQUEST_DENIED" {
+		t.Errorf("got status %v for wrong key, want REQUEST_DENIED", body["status"])
+	}
+	if _, body := get(t, s.MapsURL()+"geocode/json?address=NYC&key=key"); body["status"] != "ZERO_RESULTS" {
+		t.Errorf("got status %v after denied request, want ZERO_RESULTS", body["status"])
+	}
+}
@@ -0,0 +1,143 @@
+package maps
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultPolylinePrecision is the number of decimal digits of precision used by the Google Maps APIs to encode polylines.
+//
+// Some other services, such as OSRM, encode polylines with 6 digits of precision.
+const DefaultPolylinePrecision = 5
+
+// PolylineError indicates that an encoded polyline could not be decoded.
+type PolylineError struct {
+	// Offset is the offset into the encoded polyline at which the error was found.
+	Offset int
+
+	// Reason describes the error.
+	Reason string
+}
+
+func (e PolylineError) Error() string {
+	return fmt.Sprintf("invalid polyline at offset %d: %s", e.Offset, e.Reason)
+}
+
+// Decode decodes the Points of the polyline into a series of locations, using DefaultPolylinePrecision.
+//
+// See https://developers.google.com/maps/documentation/utilities/polylinealgorithm
+func (p Polyline) Decode() ([]LatLng, error) {
+	return p.DecodePrecision(DefaultPolylinePrecision)
+}
+
+// DecodePrecision decodes the Points of the polyline into a series of locations, using the given number of decimal digits of precision.
+//
+// Precision must be between 1 and 10. A PolylineError is returned if the polyline is malformed, or if it describes a location out of range.
+func (p Polyline) DecodePrecision(precision int) ([]LatLng, error) {
+	if precision < 1 || precision > 10 {
+		return nil, fmt.Errorf("invalid polyline precision %d", precision)
+	}
+	factor := math.Pow10(precision)
+	s := p.Points
+	var ll []LatLng
+	var lat, lng int64
+	for i := 0; i < len(s); {
+		start := i
+		dlat, n, err := decodeValue(s, i)
+		if err != nil {
+			return nil, err
+		}
+		i = n
+		if i == len(s) {
+			return nil, PolylineError{i, "missing longitude"}
+		}
+		dlng, n, err := decodeValue(s, i)
+		if err != nil {
+			return nil, err
+		}
+		lat += dlat
+		lng += dlng
+		l := LatLng{float64(lat) / factor, float64(lng) / factor}
+		if math.Abs(l.Lat) > 90 || math.Abs(l.Lng) > 180 {
+			return nil, PolylineError{start, fmt.Sprintf("location %s out of range", l)}
+		}
+		i = n
+		ll = append(ll, l)
+	}
+	return ll, nil
+}
+
+// decodeValue decodes the value starting at offset i of s, and returns it along with the offset of the next value.
+func decodeValue(s string, i int) (int64, int, error) {
+	var result uint64
+	for shift := uint(0); ; shift += 5 {
+		if i >= len(s) {
+			return 0, i, PolylineError{i, "unexpected end of polyline"}
+		}
+		if shift > 60 {
+			return 0, i, PolylineError{i, "value overflows 64 bits"}
+		}
+		c := s[i]
+		if c < 63 || c > 126 {
+			return 0, i, PolylineError{i, fmt.Sprintf("invalid character %q", c)}
+		}
+		b := uint64(c - 63)
+		if shift == 60 && b&0x1f > 0xf {
+			// Only the low 4 bits of the final chunk fit in 64 bits.
+			return 0, i, PolylineError{i, "value overflows 64 bits"}
+		}
+		result |= (b & 0x1f) << shift
+		i++
+		if b < 0x20 {
+			break
+		}
+	}
+	v := int64(result >> 1)
+	if result&1 != 0 {
+		v = ^v
+	}
+	return v, i, nil
+}
+
+// EncodePolyline returns a Polyline describing the given locations, encoded using DefaultPolylinePrecision.
+//
+// See https://developers.google.com/maps/documentation/utilities/polylinealgorithm
+func EncodePolyline(ll []LatLng) Polyline {
+	return encodePolyline(ll, DefaultPolylinePrecision)
+}
+
+// EncodePolylinePrecision returns a Polyline describing the given locations, encoded using the given number of decimal digits of precision.
+//
+// Precision must be between 1 and 10, as for DecodePrecision.
+func EncodePolylinePrecision(ll []LatLng, precision int) (Polyline, error) {
+	if precision < 1 || precision > 10 {
+		return Polyline{}, fmt.Errorf("invalid polyline precision %d", precision)
+	}
+	return encodePolyline(ll, precision), nil
+}
+
+func encodePolyline(ll []LatLng, precision int) Polyline {
+	factor := math.Pow10(precision)
+	var sb strings.Builder
+	var plat, plng int64
+	for _, l := range ll {
+		lat, lng := int64(math.Round(l.Lat*factor)), int64(math.Round(l.Lng*factor))
+		encodeValue(&sb, lat-plat)
+		encodeValue(&sb, lng-plng)
+		plat, plng = lat, lng
+	}
+	return Polyline{Points: sb.String()}
+}
+
+func encodeValue(sb *strings.Builder, v int64) {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		sb.WriteByte(byte(0x20|(u&0x1f)) + 63)
+		u >>= 5
+	}
+	sb.WriteByte(byte(u) + 63)
+}
@@ -0,0 +1,121 @@
+package maps
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// Based on https://developers.google.com/maps/documentation/utilities/polylinealgorithm
+func TestPolyline(t *testing.T) {
+	ll := []LatLng{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}}
+	exp := "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+	if p := EncodePolyline(ll); p.Points != exp {
+		t.Errorf("got %q, want %q", p.Points, exp)
+	}
+	got, err := Polyline{Points: exp}.Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, ll) {
+		t.Errorf("got %v, want %v", got, ll)
+	}
+
+	ll = []LatLng{{40.714224, -73.961452}, {40.714624, -73.961}}
+	p, err := EncodePolylinePrecision(ll, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := p.DecodePrecision(6); err != nil || !reflect.DeepEqual(got, ll) {
+		t.Errorf("got %v, %v, want %v", got, err, ll)
+	}
+
+	// Encoding and decoding both reject an invalid precision.
+	for _, precision := range []int{0, 11} {
+		if _, err := EncodePolylinePrecision(ll, precision); err == nil {
+			t.Errorf("expected error encoding with precision %d", precision)
+		}
+		if _, err := p.DecodePrecision(precision); err == nil {
+			t.Errorf("expected error decoding with precision %d", precision)
+		}
+	}
+}
+
+func TestPolylineErrors(t *testing.T) {
+	for _, c := range []struct {
+		points string
+		offset int
+	}{
+		{"_p~iF", 5},            // missing longitude
+		{"_p~iF~ps|", 9},        // truncated longitude
+		{"_p~iF~ps|U_ul", 13},   // truncated latitude
+		{"_p~iF~ps U", 8},       // invalid character
+		{"______________?", 13}, // overflow
+		{"____________O?", 12},  // overflow in the final chunk
+		{"_gsia@?", 0},          // latitude out of range
+	} {
+		_, err := Polyline{Points: c.points}.Decode()
+		var pe PolylineError
+		if !errors.As(err, &pe) {
+			t.Errorf("%q: got error %v, want PolylineError", c.points, err)
+			continue
+		}
+		if pe.Offset != c.offset {
+			t.Errorf("%q: got offset %d, want %d (%v)", c.points, pe.Offset, c.offset, err)
+		}
+	}
+	if _, err := (Polyline{Points: "??"}).DecodePrecision(0); err == nil {
+		t.Errorf("expected error for invalid precision")
+	}
+}
+
+func FuzzPolylineRoundTrip(f *testing.F) {
+	f.Add(38.5, -120.2, 40.7, -120.95, 5)
+	f.Add(-90.0, 180.0, 90.0, -180.0, 6)
+	f.Add(0.0, 0.0, 0.0, 0.0, 1)
+	f.Fuzz(func(t *testing.T, lat1, lng1, lat2, lng2 float64, precision int) {
+		if precision < 1 || precision > 6 {
+			return
+		}
+		ll := []LatLng{{lat1, lng1}, {lat2, lng2}}
+		for _, l := range ll {
+			if !(math.Abs(l.Lat) <= 90) || !(math.Abs(l.Lng) <= 180) {
+				return
+			}
+		}
+		p, err := EncodePolylinePrecision(ll, precision)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := p.DecodePrecision(precision)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		factor := math.Pow10(precision)
+		for i, l := range ll {
+			exp := LatLng{math.Round(l.Lat*factor) / factor, math.Round(l.Lng*factor) / factor}
+			if got[i] != exp {
+				t.Errorf("point %d: got %v, want %v", i, got[i], exp)
+			}
+		}
+	})
+}
+
+func FuzzPolylineDecode(f *testing.F) {
+	f.Add("_p~iF~ps|U_ulLnnqC_mqNvxq`@")
+	f.Add("gfo}EtohhUxD@bAxJmGF")
+	f.Fuzz(func(t *testing.T, points string) {
+		ll, err := Polyline{Points: points}.Decode()
+		if err != nil {
+			return
+		}
+		got, err := EncodePolyline(ll).Decode()
+		if err != nil {
+			t.Fatalf("unexpected error re-decoding %q: %v", points, err)
+		}
+		if !reflect.DeepEqual(got, ll) {
+			t.Errorf("got %v, want %v", got, ll)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package projection
+
+import (
+	"fmt"
+	"math"
+
+	maps "github.com/imjasonh/staticmaps"
+)
+
+// MaxZoom is the highest zoom level returned by Fit.
+const MaxZoom = 21
+
+// Fit returns the center of the bounds, and the largest zoom level at which they fit within an image of the given size,
+// leaving at least padding pixels between the bounds and each edge of the image.
+//
+// Bounds whose Southwest longitude is greater than their Northeast longitude are taken to cross the antimeridian.
+// If the bounds do not fit even at zoom level 0, zoom level 0 is returned.
+func Fit(b maps.Bounds, s maps.Size, padding int) (maps.LatLng, int) {
+	ne, sw := LatLngToWorld(b.Northeast), LatLngToWorld(b.Southwest)
+	if ne.X < sw.X {
+		ne.X += TileSize
+	}
+	w, h := ne.X-sw.X, sw.Y-ne.Y
+	aw, ah := float64(s.W-2*padding), float64(s.H-2*padding)
+	zoom := 0
+	for z := MaxZoom; z > 0; z-- {
+		if w*scale(z) <= aw && h*scale(z) <= ah {
+			zoom = z
+			break
+		}
+	}
+	c := WorldToLatLng(Point{math.Mod(sw.X+w/2, TileSize), ne.Y + h/2})
+	return c, zoom
+}
+
+// FitLatLngs returns the center and zoom level at which all of the locations fit within an image of the given size,
+// as described by Fit.
+func FitLatLngs(ll []maps.LatLng, s maps.Size, padding int) (maps.LatLng, int) {
+	return Fit(maps.NewBounds(ll...), s, padding)
+}
+
+// FitStaticMap returns the center and zoom level at which all of the markers, paths and visible locations
+// of opts fit within an image of the given size, as described by Fit.
+//
+// An error is returned if any of the locations is not a LatLng, since it cannot be projected without being geocoded,
+// or if opts contains no locations.
+func FitStaticMap(opts *maps.StaticMapOpts, s maps.Size, padding int) (maps.LatLng, int, error) {
+	var ll []maps.LatLng
+	add := func(ls []maps.Location) error {
+		for _, l := range ls {
+			switch l := l.(type) {
+			case maps.LatLng:
+				ll = append(ll, l)
+			case *maps.LatLng:
+				ll = append(ll, *l)
+			default:
+				return fmt.Errorf("cannot fit location %q which is not a LatLng", l.Location())
+			}
+		}
+		return nil
+	}
+	for _, m := range opts.Markers {
+		if err := add(m.Locations); err != nil {
+			return maps.LatLng{}, 0, err
+		}
+	}
+	for _, p := range opts.Paths {
+		if p.Polyline != "" {
+			pl, err := maps.Polyline{Points: p.Polyline}.Decode()
+			if err != nil {
+				return maps.LatLng{}, 0, err
+			}
+			ll = append(ll, pl...)
+		} else if err := add(p.Locations); err != nil {
+			return maps.LatLng{}, 0, err
+		}
+	}
+	if err := add(opts.Visible); err != nil {
+		return maps.LatLng{}, 0, err
+	}
+	if len(ll) == 0 {
+		return maps.LatLng{}, 0, fmt.Errorf("no locations to fit")
+	}
+	c, z := FitLatLngs(ll, s, padding)
+	return c, z, nil
+}
@@ -0,0 +1,62 @@
+package projection
+
+import (
+	"image/color"
+	"testing"
+
+	maps "github.com/imjasonh/staticmaps"
+)
+
+func TestFit(t *testing.T) {
+	s := maps.Size{H: 400, W: 600}
+	b := maps.Bounds{
+		Northeast: maps.LatLng{Lat: 40.82, Lng: -73.93},
+		Southwest: maps.LatLng{Lat: 40.70, Lng: -74.02},
+	}
+	c, z := Fit(b, s, 10)
+	if z != 11 {
+		t.Errorf("got zoom %d, want 11", z)
+	}
+	// Every corner of the bounds is within the padded image.
+	v := MapViewport{Center: c, Zoom: z, Size: s}
+	for _, ll := range []maps.LatLng{b.Northeast, b.Southwest} {
+		if p := v.LatLngToPixel(ll); p.X < 10 || p.X > 590 || p.Y < 10 || p.Y > 390 {
+			t.Errorf("%v is at %v, outside of padded image", ll, p)
+		}
+	}
+	// But not at the next zoom level.
+	v.Zoom++
+	p1, p2 := v.LatLngToPixel(b.Northeast), v.LatLngToPixel(b.Southwest)
+	if p2.Y-p1.Y <= 380 && p1.X-p2.X <= 580 {
+		t.Errorf("bounds also fit at zoom %d", v.Zoom)
+	}
+
+	// Bounds which cross the antimeridian are centered on it.
+	c, _ = FitLatLngs([]maps.LatLng{{Lat: -17, Lng: 178}, {Lat: -18, Lng: -179}}, s, 0)
+	if !near(c.Lng, 179.5, 1e-9) {
+		t.Errorf("got center %v, want longitude 179.5", c)
+	}
+}
+
+func TestFitStaticMap(t *testing.T) {
+	s := maps.Size{H: 256, W: 256}
+	opts := &maps.StaticMapOpts{
+		Markers: []maps.Markers{{Color: color.Black, Locations: []maps.Location{maps.LatLng{Lat: 38.5, Lng: -120.2}}}},
+		Paths:   []maps.Path{{Polyline: "_p~iF~ps|U_ulLnnqC_mqNvxq`@"}},
+	}
+	c, z, err := FitStaticMap(opts, s, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if z != 5 {
+		t.Errorf("got zoom %d, want 5", z)
+	}
+	if c.Lat < 38.5 || c.Lat > 43.252 || c.Lng < -126.453 || c.Lng > -120.2 {
+		t.Errorf("got center %v, outside of locations", c)
+	}
+
+	opts.Visible = []maps.Location{maps.Address("New York")}
+	if _, _, err := FitStaticMap(opts, s, 0); err == nil {
+		t.Errorf("expected error for Address")
+	}
+}
@@ -0,0 +1,166 @@
+// Package projection converts between locations and the Web Mercator world, pixel and tile coordinates used by Google Maps,
+// for example to position overlays on images returned by maps.StaticMap.
+//
+// World coordinates range from 0 to TileSize in each dimension at every zoom level. Pixel coordinates are world coordinates
+// scaled by 2^zoom, so that each zoom level doubles the number of pixels in each dimension. Tile coordinates identify
+// the TileSize-pixel square tile containing a pixel.
+//
+// See https://developers.google.com/maps/documentation/javascript/coordinates
+package projection
+
+import (
+	"math"
+
+	maps "github.com/imjasonh/staticmaps"
+)
+
+// TileSize is the size of a map tile in pixels, and of the world in world coordinates.
+const TileSize = 256
+
+// MaxLatitude is the maximum latitude that can be projected; locations further north or south are clamped to it.
+var MaxLatitude = 2*math.Atan(math.Exp(math.Pi))*180/math.Pi - 90
+
+// Point is a point in world or pixel coordinates, where X increases eastward and Y increases southward.
+type Point struct {
+	X, Y float64
+}
+
+// Tile identifies a map tile at a zoom level.
+type Tile struct {
+	X, Y, Z int
+}
+
+// LatLngToWorld returns the world coordinates of the location.
+func LatLngToWorld(ll maps.LatLng) Point {
+	lat := math.Max(-MaxLatitude, math.Min(MaxLatitude, ll.Lat))
+	siny := math.Sin(lat * math.Pi / 180)
+	return Point{
+		X: TileSize * (0.5 + ll.Lng/360),
+		Y: TileSize * (0.5 - math.Log((1+siny)/(1-siny))/(4*math.Pi)),
+	}
+}
+
+// WorldToLatLng returns the location at the world coordinates.
+func WorldToLatLng(p Point) maps.LatLng {
+	n := math.Pi - 2*math.Pi*p.Y/TileSize
+	return maps.LatLng{
+		Lat: math.Atan(math.Sinh(n)) * 180 / math.Pi,
+		Lng: p.X/TileSize*360 - 180,
+	}
+}
+
+// scale returns the number of pixels per world coordinate at the zoom level.
+func scale(zoom int) float64 {
+	return math.Exp2(float64(zoom))
+}
+
+// WorldToPixel returns the pixel coordinates of the world coordinates at the zoom level.
+func WorldToPixel(p Point, zoom int) Point {
+	s := scale(zoom)
+	return Point{p.X * s, p.Y * s}
+}
+
+// PixelToWorld returns the world coordinates of the pixel coordinates at the zoom level.
+func PixelToWorld(p Point, zoom int) Point {
+	s := scale(zoom)
+	return Point{p.X / s, p.Y / s}
+}
+
+// LatLngToPixel returns the pixel coordinates of the location at the zoom level.
+func LatLngToPixel(ll maps.LatLng, zoom int) Point {
+	return WorldToPixel(LatLngToWorld(ll), zoom)
+}
+
+// PixelToLatLng returns the location at the pixel coordinates at the zoom level.
+func PixelToLatLng(p Point, zoom int) maps.LatLng {
+	return WorldToLatLng(PixelToWorld(p, zoom))
+}
+
+// PixelToTile returns the tile containing the pixel coordinates at the zoom level.
+func PixelToTile(p Point, zoom int) Tile {
+	return Tile{int(math.Floor(p.X / TileSize)), int(math.Floor(p.Y / TileSize)), zoom}
+}
+
+// LatLngToTile returns the tile containing the location at the zoom level.
+func LatLngToTile(ll maps.LatLng, zoom int) Tile {
+	return PixelToTile(LatLngToPixel(ll, zoom), zoom)
+}
+
+// Origin returns the pixel coordinates of the northwest corner of the tile.
+func (t Tile) Origin() Point {
+	return Point{float64(t.X * TileSize), float64(t.Y * TileSize)}
+}
+
+// Bounds returns the bounding box of the tile.
+func (t Tile) Bounds() maps.Bounds {
+	o := t.Origin()
+	return maps.Bounds{
+		Northeast: PixelToLatLng(Point{o.X + TileSize, o.Y}, t.Z),
+		Southwest: PixelToLatLng(Point{o.X, o.Y + TileSize}, t.Z),
+	}
+}
+
+// MapViewport describes the area shown by an image returned by maps.StaticMap, and converts between locations and
+// the image's pixel coordinates, where (0, 0) is its top left corner.
+type MapViewport struct {
+	// Center is the location at the center of the image.
+	Center maps.LatLng
+
+	// Zoom is the zoom level of the image.
+	Zoom int
+
+	// Size is the size of the image requested, in pixels.
+	Size maps.Size
+
+	// Scale is the scale of the image requested, as in maps.StaticMapOpts. If zero, a scale of 1 is used.
+	//
+	// An image with a Scale of 2 contains twice as many pixels in each dimension as its Size, covering the same area.
+	Scale int
+}
+
+func (v MapViewport) scale() float64 {
+	if v.Scale == 0 {
+		return 1
+	}
+	return float64(v.Scale)
+}
+
+// origin returns the pixel coordinates of the top left corner of the image at the viewport's zoom level.
+func (v MapViewport) origin() Point {
+	c := LatLngToPixel(v.Center, v.Zoom)
+	return Point{c.X - float64(v.Size.W)/2, c.Y - float64(v.Size.H)/2}
+}
+
+// LatLngToPixel returns the coordinates of the location in the image.
+//
+// The coordinates may be outside of the image if the location is not shown in it.
+func (v MapViewport) LatLngToPixel(ll maps.LatLng) Point {
+	p, o, s := LatLngToPixel(ll, v.Zoom), v.origin(), v.scale()
+	x := p.X - o.X
+	// Choose the copy of the location nearest the center, since the map wraps around horizontally.
+	world := TileSize * scale(v.Zoom)
+	for x-float64(v.Size.W)/2 > world/2 {
+		x -= world
+	}
+	for float64(v.Size.W)/2-x > world/2 {
+		x += world
+	}
+	return Point{x * s, (p.Y - o.Y) * s}
+}
+
+// PixelToLatLng returns the location at the coordinates in the image.
+func (v MapViewport) PixelToLatLng(p Point) maps.LatLng {
+	o, s := v.origin(), v.scale()
+	ll := PixelToLatLng(Point{o.X + p.X/s, o.Y + p.Y/s}, v.Zoom)
+	ll.Lng = math.Mod(ll.Lng+540, 360) - 180
+	return ll
+}
+
+// Bounds returns the bounding box of the area shown in the image.
+func (v MapViewport) Bounds() maps.Bounds {
+	w, h := float64(v.Size.W)*v.scale(), float64(v.Size.H)*v.scale()
+	return maps.Bounds{
+		Northeast: v.PixelToLatLng(Point{w, 0}),
+		Southwest: v.PixelToLatLng(Point{0, h}),
+	}
+}
@@ -0,0 +1,66 @@
+package projection
+
+import (
+	"math"
+	"testing"
+
+	maps "github.com/imjasonh/staticmaps"
+)
+
+const epsilon = 1e-9
+
+func near(a, b, eps float64) bool {
+	return math.Abs(a-b) <= eps
+}
+
+// Based on https://developers.google.com/maps/documentation/javascript/examples/map-coordinates
+func TestProjection(t *testing.T) {
+	chicago := maps.LatLng{Lat: 41.85, Lng: -87.65}
+	w := LatLngToWorld(chicago)
+	if !near(w.X, 65.67111111111111, epsilon) || !near(w.Y, 95.17492654697409, epsilon) {
+		t.Errorf("got world coordinates %v", w)
+	}
+	p := LatLngToPixel(chicago, 3)
+	if math.Floor(p.X) != 525 || math.Floor(p.Y) != 761 {
+		t.Errorf("got pixel coordinates %v, want (525, 761)", p)
+	}
+	if tile, exp := LatLngToTile(chicago, 3), (Tile{2, 2, 3}); tile != exp {
+		t.Errorf("got tile %v, want %v", tile, exp)
+	}
+	if ll := PixelToLatLng(p, 3); !near(ll.Lat, chicago.Lat, epsilon) || !near(ll.Lng, chicago.Lng, epsilon) {
+		t.Errorf("got %v, want %v", ll, chicago)
+	}
+
+	b := Tile{0, 0, 0}.Bounds()
+	if !near(b.Northeast.Lat, MaxLatitude, epsilon) || !near(b.Northeast.Lng, 180, epsilon) ||
+		!near(b.Southwest.Lat, -MaxLatitude, epsilon) || !near(b.Southwest.Lng, -180, epsilon) {
+		t.Errorf("got bounds %v for world tile", b)
+	}
+}
+
+func TestMapViewport(t *testing.T) {
+	nyc := maps.LatLng{Lat: 40.714224, Lng: -73.961452}
+	v := MapViewport{Center: nyc, Zoom: 12, Size: maps.Size{H: 300, W: 400}, Scale: 2}
+	if p := v.LatLngToPixel(nyc); !near(p.X, 400, epsilon) || !near(p.Y, 300, epsilon) {
+		t.Errorf("got center at %v, want (400, 300)", p)
+	}
+	for _, p := range []Point{{0, 0}, {800, 600}, {123, 456}} {
+		got := v.LatLngToPixel(v.PixelToLatLng(p))
+		if !near(got.X, p.X, 1e-6) || !near(got.Y, p.Y, 1e-6) {
+			t.Errorf("got %v, want %v", got, p)
+		}
+	}
+	b := v.Bounds()
+	if !(b.Southwest.Lat < nyc.Lat && nyc.Lat < b.Northeast.Lat && b.Southwest.Lng < nyc.Lng && nyc.Lng < b.Northeast.Lng) {
+		t.Errorf("bounds %v do not contain center %v", b, nyc)
+	}
+
+	// Locations across the antimeridian are placed next to the center.
+	v = MapViewport{Center: maps.LatLng{Lat: 0, Lng: 179}, Zoom: 4, Size: maps.Size{H: 256, W: 256}}
+	if p := v.LatLngToPixel(maps.LatLng{Lat: 0, Lng: -179}); !(p.X > 128 && p.X < 256) {
+		t.Errorf("got %v, want location to the right of center", p)
+	}
+	if ll := v.PixelToLatLng(Point{256, 128}); !near(ll.Lng, -169.75, epsilon) {
+		t.Errorf("got %v, want longitude wrapped to the western hemisphere", ll)
+	}
+}
@@ -0,0 +1,129 @@
+package maps
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate of requests made to each Service, using a token bucket for each.
+//
+// A RateLimiter is safe for concurrent use by multiple goroutines.
+// The zero RateLimiter does not limit requests unless a limit is set for their Service with SetLimit.
+type RateLimiter struct {
+	mu      sync.Mutex
+	qps     float64
+	burst   int
+	buckets map[Service]*bucket
+	now     func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter which allows up to qps requests per second to each Service, with bursts of up to burst requests.
+//
+// If qps is zero or negative, requests are not limited unless a limit is set for their Service with SetLimit.
+func NewRateLimiter(qps float64, burst int) *RateLimiter {
+	return &RateLimiter{qps: qps, burst: burst}
+}
+
+// SetLimit sets the limit for requests to the given Service, overriding the limit provided to NewRateLimiter.
+//
+// If qps is zero or negative, requests to the Service are not limited.
+func (rl *RateLimiter) SetLimit(svc Service, qps float64, burst int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.buckets == nil {
+		rl.buckets = map[Service]*bucket{}
+	}
+	rl.buckets[svc] = newBucket(qps, burst, rl.clock())
+}
+
+// Wait blocks until a request to the given Service is allowed, or until ctx is done, in which case the context's error is returned.
+func (rl *RateLimiter) Wait(ctx context.Context, svc Service) error {
+	rl.mu.Lock()
+	b := rl.bucket(svc)
+	d := b.reserve(rl.clock())
+	rl.mu.Unlock()
+	if d == 0 {
+		return nil
+	}
+	if err := sleep(ctx, d); err != nil {
+		rl.mu.Lock()
+		b.tokens = math.Min(b.tokens+1, b.burst)
+		rl.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Delay returns how long a request to the given Service would currently have to wait, without reserving it.
+//
+// This is intended for monitoring; the delay may change before the next call to Wait.
+func (rl *RateLimiter) Delay(svc Service) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	b := rl.bucket(svc)
+	b.advance(rl.clock())
+	return b.delay(b.tokens)
+}
+
+// bucket returns the bucket for svc, creating it if necessary. rl.mu must be held.
+func (rl *RateLimiter) bucket(svc Service) *bucket {
+	if rl.buckets == nil {
+		rl.buckets = map[Service]*bucket{}
+	}
+	b, ok := rl.buckets[svc]
+	if !ok {
+		b = newBucket(rl.qps, rl.burst, rl.clock())
+		rl.buckets[svc] = b
+	}
+	return b
+}
+
+// clock returns the current time, from rl.now if it is set.
+func (rl *RateLimiter) clock() time.Time {
+	if rl.now != nil {
+		return rl.now()
+	}
+	return time.Now()
+}
+
+type bucket struct {
+	qps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(qps float64, burst int, now time.Time) *bucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucket{qps: qps, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// advance adds the tokens accumulated since the last call.
+func (b *bucket) advance(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.qps)
+	}
+	b.last = now
+}
+
+// reserve takes a token and returns how long to wait until it may be used.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	if b.qps <= 0 {
+		return 0
+	}
+	b.advance(now)
+	b.tokens--
+	return b.delay(b.tokens + 1)
+}
+
+// delay returns how long to wait until the given number of tokens reaches one.
+func (b *bucket) delay(tokens float64) time.Duration {
+	if b.qps <= 0 || tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - tokens) / b.qps * float64(time.Second))
+}
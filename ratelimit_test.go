@@ -0,0 +1,105 @@
+package maps
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	rl := NewRateLimiter(10, 2)
+	rl.now = func() time.Time { return now }
+	rl.SetLimit(ServiceStaticMap, 0, 0)
+
+	for i := 0; i < 2; i++ {
+		if d := rl.Delay(ServiceGeocode); d != 0 {
+			t.Errorf("request %d: got delay %s, want 0", i, d)
+		}
+		if err := rl.Wait(context.Background(), ServiceGeocode); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if d, exp := rl.Delay(ServiceGeocode), 100*time.Millisecond; d != exp {
+		t.Errorf("got delay %s, want %s", d, exp)
+	}
+	// Other services are limited separately.
+	if d := rl.Delay(ServiceDirections); d != 0 {
+		t.Errorf("got delay %s for directions, want 0", d)
+	}
+	for i := 0; i < 100; i++ {
+		if d := rl.Delay(ServiceStaticMap); d != 0 {
+			t.Fatalf("got delay %s for unlimited service, want 0", d)
+		}
+	}
+
+	now = now.Add(50 * time.Millisecond)
+	if d, exp := rl.Delay(ServiceGeocode), 50*time.Millisecond; d != exp {
+		t.Errorf("got delay %s, want %s", d, exp)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := rl.Wait(ctx, ServiceGeocode); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+	// The cancelled request's reservation is returned.
+	if d, exp := rl.Delay(ServiceGeocode), 50*time.Millisecond; d != exp {
+		t.Errorf("got delay %s after cancellation, want %s", d, exp)
+	}
+}
+
+func TestRateLimiterCanceledRefund(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Unix(0, 0)
+	rl := NewRateLimiter(10, 1)
+	rl.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	if err := rl.Wait(context.Background(), ServiceGeocode); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- rl.Wait(ctx, ServiceGeocode) }()
+	// Wait for the request to be reserved.
+	for rl.Delay(ServiceGeocode) != 200*time.Millisecond {
+		time.Sleep(time.Millisecond)
+	}
+	// The bucket refills while the request is waiting, and then the request is canceled.
+	mu.Lock()
+	now = now.Add(time.Hour)
+	mu.Unlock()
+	rl.Delay(ServiceGeocode)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	// The refunded token does not exceed the burst.
+	if err := rl.Wait(context.Background(), ServiceGeocode); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d, exp := rl.Delay(ServiceGeocode), 100*time.Millisecond; d != exp {
+		t.Errorf("got delay %s, want %s", d, exp)
+	}
+}
+
+func TestRateLimiterZero(t *testing.T) {
+	var rl RateLimiter
+	if err := rl.Wait(context.Background(), ServiceGeocode); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := rl.Delay(ServiceGeocode); d != 0 {
+		t.Errorf("got delay %s, want 0", d)
+	}
+	rl.SetLimit(ServiceGeocode, 1, 1)
+	if err := rl.Wait(context.Background(), ServiceGeocode); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := rl.Delay(ServiceGeocode); d <= 0 {
+		t.Errorf("got delay %s, want > 0", d)
+	}
+}
@@ -0,0 +1,196 @@
+// Package replay provides http.RoundTrippers that record exchanges with the Google Maps web services to cassette files,
+// and replay them later, so that tests can run deterministically and offline.
+//
+// Record real responses once, with a Recorder installed in the http.Client given to maps.NewContext:
+//
+//	rec := replay.NewRecorder("testdata/directions.json", http.DefaultTransport)
+//	ctx := maps.NewContext(key, &http.Client{Transport: rec})
+//	...
+//	if err := rec.Save(); err != nil { ... }
+//
+// Then replay them with a Replayer:
+//
+//	rep, err := replay.NewReplayer("testdata/directions.json")
+//	ctx := maps.NewContext("", &http.Client{Transport: rep})
+//
+// Credentials in the key, client and signature query parameters are never written to cassettes.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// redacted lists the query parameters which contain credentials, and which are ignored when matching requests.
+var redacted = []string{"key", "client", "signature"}
+
+// Interaction describes a recorded request and its response.
+type Interaction struct {
+	// Method is the HTTP method of the request.
+	Method string `json:"method"`
+
+	// URL is the normalized URL of the request: its path and sorted query parameters, without credentials.
+	URL string `json:"url"`
+
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int `json:"status_code"`
+
+	// Header contains the headers of the response.
+	Header http.Header `json:"header"`
+
+	// Body is the body of the response.
+	Body []byte `json:"body"`
+}
+
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// normalize returns the path and sorted query parameters of u, without credentials.
+func normalize(u *url.URL) string {
+	q := u.Query()
+	for _, k := range redacted {
+		q.Del(k)
+	}
+	if len(q) == 0 {
+		return u.Path
+	}
+	return u.Path + "?" + q.Encode()
+}
+
+// Recorder is an http.RoundTripper that records each exchange made with its Transport.
+//
+// A Recorder is safe for concurrent use by multiple goroutines.
+type Recorder struct {
+	// Transport is used to make requests. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	path         string
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder returns a Recorder which makes requests with the provided http.RoundTripper, and saves them to the cassette file at path.
+func NewRecorder(path string, rt http.RoundTripper) *Recorder {
+	return &Recorder{Transport: rt, path: path}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	t := r.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	resp, err := t.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, Interaction{
+		Method:     req.Method,
+		URL:        normalize(req.URL),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+	})
+	return resp, nil
+}
+
+// Save writes the recorded interactions to the cassette file, replacing its contents.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	b, err := json.MarshalIndent(cassette{r.interactions}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, b, 0644)
+}
+
+// Replayer is an http.RoundTripper that responds to requests with interactions from a cassette file, without making any requests.
+//
+// Requests are matched by method, path and query parameters, ignoring credentials. If a request matches
+// multiple interactions, they are replayed in the order they were recorded, and the last is repeated.
+//
+// A Replayer is safe for concurrent use by multiple goroutines.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+}
+
+// NewReplayer returns a Replayer which replays the interactions in the cassette file at path.
+func NewReplayer(path string) (*Replayer, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("reading cassette %s: %v", path, err)
+	}
+	r := &Replayer{interactions: map[string][]Interaction{}}
+	for _, i := range c.Interactions {
+		k := i.Method + " " + i.URL
+		r.interactions[k] = append(r.interactions[k], i)
+	}
+	return r, nil
+}
+
+// NoInteractionError indicates that a Replayer has no recorded interaction matching a request.
+type NoInteractionError struct {
+	// Method and URL describe the unmatched request; URL is normalized to exclude credentials.
+	Method, URL string
+}
+
+func (e NoInteractionError) Error() string {
+	return fmt.Sprintf("no recorded interaction for %s %s", e.Method, e.URL)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := normalize(req.URL)
+	k := req.Method + " " + u
+	r.mu.Lock()
+	is := r.interactions[k]
+	if len(is) == 0 {
+		r.mu.Unlock()
+		return nil, NoInteractionError{req.Method, u}
+	}
+	i := is[0]
+	if len(is) > 1 {
+		r.interactions[k] = is[1:]
+	}
+	r.mu.Unlock()
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", i.StatusCode, http.StatusText(i.StatusCode)),
+		StatusCode:    i.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        i.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(i.Body)),
+		ContentLength: int64(len(i.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Exists reports whether a cassette file exists at path, which is useful to decide whether to record or replay.
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,80 @@
+package replay_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	maps "github.com/imjasonh/staticmaps"
+	"github.com/imjasonh/staticmaps/mapstest"
+	"github.com/imjasonh/staticmaps/replay"
+)
+
+func TestRecordReplay(t *testing.T) {
+	fake := mapstest.NewServer()
+	fake.Enqueue(mapstest.Geocode, mapstest.JSON(map[string]interface{}{
+		"status":  "OK",
+		"results": []interface{}{map[string]string{"formatted_address": "New York, NY, USA"}},
+	}))
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec := replay.NewRecorder(path, http.DefaultTransport)
+	c, err := maps.NewClient(maps.WithAPIKey("secret-key"), maps.WithBaseURL(fake.MapsURL()), maps.WithHTTPClient(&http.Client{Transport: rec}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	opts := &maps.GeocodeOpts{Address: "New York"}
+	if _, err := c.Geocode(ctx, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, err := c.StaticMap(ctx, maps.Size{H: 1, W: 1}, &maps.StaticMapOpts{Center: maps.Address("New York")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorded, _ := ioutil.ReadAll(img)
+	img.Close()
+	if err := rec.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fake.Close()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(b), "secret-key") {
+		t.Errorf("cassette contains API key:\n%s", b)
+	}
+
+	rep, err := replay.NewReplayer(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx = maps.NewContext("other-key", &http.Client{Transport: rep})
+	r, err := maps.Geocode(ctx, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r) != 1 || r[0].FormattedAddress != "New York, NY, USA" {
+		t.Errorf("unexpected results: %v", r)
+	}
+	img, err = maps.StaticMap(ctx, maps.Size{H: 1, W: 1}, &maps.StaticMapOpts{Center: maps.Address("New York")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	replayed, _ := ioutil.ReadAll(img)
+	img.Close()
+	if string(replayed) != string(recorded) {
+		t.Errorf("replayed image differs from recorded image")
+	}
+
+	_, err = maps.Geocode(ctx, &maps.GeocodeOpts{Address: "Boston"})
+	var nie replay.NoInteractionError
+	if !errors.As(err, &nie) {
+		t.Errorf("got error %v, want NoInteractionError", err)
+	}
+}
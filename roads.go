@@ -10,13 +10,23 @@ const roadsAPIBaseURL = "https://roads.googleapis.com/v1/"
 
 var ErrNoAPIKey = errors.New("must provide an API key")
 
+// SnapToRoads requests the road geometry most likely traveled along the provided path, using the Client configured in ctx.
+//
+// See https://developers.google.com/maps/documentation/roads/snap
 func SnapToRoads(ctx context.Context, path []LatLng, opts *SnapToRoadsOpts) ([]SnappedPoint, error) {
-	if key(ctx) == "" {
+	return fromContext(ctx).SnapToRoads(ctx, path, opts)
+}
+
+// SnapToRoads requests the road geometry most likely traveled along the provided path.
+//
+// See https://developers.google.com/maps/documentation/roads/snap
+func (c *Client) SnapToRoads(ctx context.Context, path []LatLng, opts *SnapToRoadsOpts) ([]SnappedPoint, error) {
+	if c.key == "" {
 		return nil, ErrNoAPIKey
 	}
 
 	var d snapToRoadsResponse
-	if err := doDecode(ctx, roadsAPIBaseURL+snapToRoads(path, opts), &d); err != nil {
+	if err := c.doDecode(ctx, ServiceRoads, snapToRoads(path, opts), &d); err != nil {
 		return nil, err
 	}
 	return d.SnappedPoints, nil
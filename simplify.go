@@ -0,0 +1,185 @@
+package maps
+
+import (
+	"context"
+	"math"
+)
+
+// Simplification describes how the paths of a StaticMapOpts were simplified to fit within MaxURLLength.
+type Simplification struct {
+	// Tolerance is the maximum distance in meters that a simplified path deviates from the original path.
+	// If zero, paths were not simplified.
+	Tolerance float64
+
+	// Precision is the number of decimal digits to which path coordinates were rounded.
+	Precision int
+
+	// Paths describes how each path was simplified, by its index in StaticMapOpts.Paths.
+	//
+	// Paths which could not be simplified, because some of their locations are not LatLngs, are not included.
+	Paths map[int]PathSimplification
+
+	// Length is the length of the resulting request URL.
+	Length int
+}
+
+// PathSimplification describes how a path was simplified.
+type PathSimplification struct {
+	// Before and After are the number of points in the path before and after it was simplified.
+	Before, After int
+}
+
+// SimplifyStaticMap returns a copy of opts whose paths are simplified as necessary for the StaticMap request URL to fit within MaxURLLength,
+// using the Client configured in ctx.
+func SimplifyStaticMap(ctx context.Context, s Size, opts *StaticMapOpts) (*StaticMapOpts, *Simplification, error) {
+	return fromContext(ctx).SimplifyStaticMap(s, opts)
+}
+
+// SimplifyStaticMap returns a copy of opts whose paths are simplified as necessary for the StaticMap request URL,
+// including credentials and signature, to fit within MaxURLLength.
+//
+// Paths are simplified using the Douglas-Peucker algorithm with increasing tolerance, and their coordinates are
+// rounded to decreasing precision, until the URL fits. Only paths specified as a Polyline or whose Locations are
+// all LatLngs can be simplified. If the URL cannot be made to fit, a URLTooLongError is returned.
+func (c *Client) SimplifyStaticMap(s Size, opts *StaticMapOpts) (*StaticMapOpts, *Simplification, error) {
+	if opts == nil {
+		opts = &StaticMapOpts{}
+	}
+	paths := map[int][]LatLng{}
+	for i, p := range opts.Paths {
+		if ll, ok := pathLatLngs(p); ok {
+			paths[i] = ll
+		}
+	}
+
+	var l int
+	// Start without simplifying anything, then double the tolerance each time the URL doesn't fit,
+	// rounding coordinates to the lowest precision whose error is within the tolerance.
+	for tol := 0.0; tol <= 2*math.Pi*EarthRadius; tol = math.Max(1, tol*2) {
+		prec := precisionFor(tol)
+		so := *opts
+		so.Paths = append([]Path(nil), opts.Paths...)
+		sim := &Simplification{Tolerance: tol, Precision: prec, Paths: map[int]PathSimplification{}}
+		for i, ll := range paths {
+			if tol > 0 {
+				ll = simplify(roundLatLngs(ll, prec), tol)
+			}
+			so.Paths[i].Polyline = EncodePolyline(ll).Points
+			so.Paths[i].Locations = nil
+			sim.Paths[i] = PathSimplification{Before: len(paths[i]), After: len(ll)}
+		}
+		u, err := c.signedURL(ServiceStaticMap, staticmap(s, &so))
+		if err != nil {
+			return nil, nil, err
+		}
+		l = len(u.String())
+		if l <= MaxURLLength {
+			sim.Length = l
+			if tol == 0 {
+				// Nothing needed to be simplified, so return the original options.
+				return opts, sim, nil
+			}
+			return &so, sim, nil
+		}
+		if len(paths) == 0 {
+			break
+		}
+	}
+	return nil, nil, URLTooLongError{Length: l, Max: MaxURLLength}
+}
+
+// pathLatLngs returns the points of the path, and whether they are all known.
+func pathLatLngs(p Path) ([]LatLng, bool) {
+	if p.Polyline != "" {
+		ll, err := Polyline{Points: p.Polyline}.Decode()
+		return ll, err == nil
+	}
+	ll := make([]LatLng, len(p.Locations))
+	for i, l := range p.Locations {
+		switch l := l.(type) {
+		case LatLng:
+			ll[i] = l
+		case *LatLng:
+			ll[i] = *l
+		default:
+			return nil, false
+		}
+	}
+	return ll, len(ll) > 0
+}
+
+// precisionFor returns the lowest number of decimal digits, up to DefaultPolylinePrecision, whose rounding error is within tol meters.
+func precisionFor(tol float64) int {
+	for p := 1; p < DefaultPolylinePrecision; p++ {
+		// Rounding a latitude to p digits moves it by up to half of 10^-p degrees.
+		if EarthRadius*math.Pi/180*math.Pow10(-p)/2 <= tol {
+			return p
+		}
+	}
+	return DefaultPolylinePrecision
+}
+
+// roundLatLngs rounds each location to prec decimal digits, removing consecutive duplicates.
+func roundLatLngs(ll []LatLng, prec int) []LatLng {
+	f := math.Pow10(prec)
+	out := make([]LatLng, 0, len(ll))
+	for _, l := range ll {
+		r := LatLng{math.Round(l.Lat*f) / f, math.Round(l.Lng*f) / f}
+		if len(out) > 0 && out[len(out)-1] == r {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// simplify simplifies the path using the Douglas-Peucker algorithm, so that it deviates from the original path by at most tol meters.
+func simplify(ll []LatLng, tol float64) []LatLng {
+	if len(ll) < 3 {
+		return ll
+	}
+	keep := make([]bool, len(ll))
+	keep[0], keep[len(ll)-1] = true, true
+	type span struct{ first, last int }
+	stack := []span{{0, len(ll) - 1}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		max, idx := 0.0, -1
+		for i := s.first + 1; i < s.last; i++ {
+			if d := segmentDistance(ll[i], ll[s.first], ll[s.last]); d > max {
+				max, idx = d, i
+			}
+		}
+		if idx >= 0 && max > tol {
+			keep[idx] = true
+			stack = append(stack, span{s.first, idx}, span{idx, s.last})
+		}
+	}
+	out := make([]LatLng, 0, len(ll))
+	for i, k := range keep {
+		if k {
+			out = append(out, ll[i])
+		}
+	}
+	return out
+}
+
+// segmentDistance returns the approximate distance in meters from p to the segment between a and b,
+// using an equirectangular projection which is accurate for short segments.
+func segmentDistance(p, a, b LatLng) float64 {
+	rad := math.Pi / 180
+	cos := math.Cos((a.Lat + b.Lat) / 2 * rad)
+	project := func(l LatLng) (float64, float64) {
+		return l.Lng * rad * cos * EarthRadius, l.Lat * rad * EarthRadius
+	}
+	px, py := project(p)
+	ax, ay := project(a)
+	bx, by := project(b)
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, ((px-ax)*dx+(py-ay)*dy)/l))
+	}
+	return math.Hypot(px-(ax+t*dx), py-(ay+t*dy))
+}
@@ -0,0 +1,68 @@
+package maps
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/imjasonh/staticmaps/mapstest"
+)
+
+func TestSimplify(t *testing.T) {
+	// Points along a straight line are removed, but the corner is kept.
+	ll := []LatLng{{0, 0}, {0, 0.5}, {0, 1}, {0.5, 1}, {1, 1}}
+	got := simplify(ll, 1)
+	exp := []LatLng{{0, 0}, {0, 1}, {1, 1}}
+	if len(got) != len(exp) {
+		t.Fatalf("got %v, want %v", got, exp)
+	}
+	for i := range got {
+		if got[i] != exp[i] {
+			t.Errorf("got %v, want %v", got, exp)
+		}
+	}
+}
+
+func TestSimplifyStaticMap(t *testing.T) {
+	c := fakeClient(t)
+	var ls []Location
+	for i := 0; i < 5000; i++ {
+		a := float64(i) / 100
+		ls = append(ls, LatLng{40 + a/100*math.Cos(a), -74 + a/100*math.Sin(a)})
+	}
+	s := Size{512, 512}
+	opts := &StaticMapOpts{
+		Paths:   []Path{{Weight: 3, Locations: ls}, {Locations: []Location{Address("New York"), Address("Boston")}}},
+		Markers: []Markers{{Locations: []Location{Address("New York")}}},
+	}
+	_, err := c.StaticMap(context.Background(), s, opts)
+	var tooLong URLTooLongError
+	if !errors.As(err, &tooLong) {
+		t.Fatalf("got error %v, want URLTooLongError", err)
+	}
+	if len(fake.Requests(mapstest.StaticMap)) != 0 {
+		t.Errorf("unexpected request with URL of length %d", tooLong.Length)
+	}
+
+	so, sim, err := c.SimplifyStaticMap(s, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Logf("simplified with tolerance %fm, precision %d: %+v", sim.Tolerance, sim.Precision, sim.Paths)
+	if sim.Length > MaxURLLength || sim.Tolerance == 0 {
+		t.Errorf("unexpected simplification %+v", sim)
+	}
+	if ps, ok := sim.Paths[0]; !ok || ps.Before != len(ls) || ps.After >= ps.Before {
+		t.Errorf("unexpected simplification of path: %+v", ps)
+	}
+	if _, ok := sim.Paths[1]; ok {
+		t.Errorf("unexpected simplification of path of addresses")
+	}
+	if len(opts.Paths[0].Locations) != len(ls) {
+		t.Errorf("original options were modified")
+	}
+	if _, err := c.StaticMap(context.Background(), s, so); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"image/color"
 	"io"
-	"net/http"
 	"net/url"
 	"strings"
 )
@@ -46,16 +45,28 @@ const (
 	VisibilitySimplified = "simplified"
 )
 
-// StaticMap requests a static map image of a requested size.
+// StaticMap requests a static map image of a requested size, using the Client configured in ctx.
 func StaticMap(ctx context.Context, s Size, opts *StaticMapOpts) (io.ReadCloser, error) {
-	resp, err := do(ctx, baseURL+staticmap(s, opts))
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, HTTPError{resp}
-	}
-	return resp.Body, nil
+	return fromContext(ctx).StaticMap(ctx, s, opts)
+}
+
+// StaticMap requests a static map image of a requested size.
+func (c *Client) StaticMap(ctx context.Context, s Size, opts *StaticMapOpts) (io.ReadCloser, error) {
+	return c.doImage(ctx, ServiceStaticMap, staticmap(s, opts))
+}
+
+// StaticMapURL returns the URL of a static map image of a requested size, using the Client configured in ctx.
+//
+// The URL includes the Client's credentials and signature, and is suitable for embedding directly in a web page, e.g., in an <img> tag.
+func StaticMapURL(ctx context.Context, s Size, opts *StaticMapOpts) (string, error) {
+	return fromContext(ctx).StaticMapURL(s, opts)
+}
+
+// StaticMapURL returns the URL of a static map image of a requested size.
+//
+// The URL includes the Client's credentials and signature, and is suitable for embedding directly in a web page, e.g., in an <img> tag.
+func (c *Client) StaticMapURL(s Size, opts *StaticMapOpts) (string, error) {
+	return c.requestURL(ServiceStaticMap, staticmap(s, opts))
 }
 
 func staticmap(s Size, opts *StaticMapOpts) string {
@@ -214,6 +225,8 @@ type Path struct {
 	Polyline string
 
 	// Locations specifies the points of the path.
+	//
+	// If all of the locations are LatLngs, they are requested as an encoded polyline when that results in a shorter URL.
 	Locations []Location
 }
 
@@ -238,7 +251,7 @@ func (p Path) encode() string {
 	if p.Polyline != "" {
 		return style + "enc:" + p.Polyline
 	}
-	return style + encodeLocations(p.Locations)
+	return style + encodePath(p.Locations)
 }
 
 // Style defines a set of rules to use to style the requested map image.
@@ -352,6 +365,7 @@ func (so *StaticMapOpts) update(p url.Values) {
 		p.Add("path", path.encode())
 	}
 	if so.Visible != nil {
+		// Unlike paths, visible locations cannot be specified as an encoded polyline.
 		p.Set("visible", encodeLocations(so.Visible))
 	}
 	for _, s := range so.Styles {
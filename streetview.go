@@ -4,20 +4,31 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 )
 
-// StreetView requests a static StreetView image of the requested size.
+// StreetView requests a static StreetView image of the requested size, using the Client configured in ctx.
 func StreetView(ctx context.Context, s Size, opts *StreetViewOpts) (io.ReadCloser, error) {
-	resp, err := do(ctx, baseURL+streetview(s, opts))
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, HTTPError{resp}
-	}
-	return resp.Body, nil
+	return fromContext(ctx).StreetView(ctx, s, opts)
+}
+
+// StreetView requests a static StreetView image of the requested size.
+func (c *Client) StreetView(ctx context.Context, s Size, opts *StreetViewOpts) (io.ReadCloser, error) {
+	return c.doImage(ctx, ServiceStreetView, streetview(s, opts))
+}
+
+// StreetViewURL returns the URL of a static StreetView image of the requested size, using the Client configured in ctx.
+//
+// The URL includes the Client's credentials and signature, and is suitable for embedding directly in a web page, e.g., in an <img> tag.
+func StreetViewURL(ctx context.Context, s Size, opts *StreetViewOpts) (string, error) {
+	return fromContext(ctx).StreetViewURL(s, opts)
+}
+
+// StreetViewURL returns the URL of a static StreetView image of the requested size.
+//
+// The URL includes the Client's credentials and signature, and is suitable for embedding directly in a web page, e.g., in an <img> tag.
+func (c *Client) StreetViewURL(s Size, opts *StreetViewOpts) (string, error) {
+	return c.requestURL(ServiceStreetView, streetview(s, opts))
 }
 
 func streetview(s Size, opts *StreetViewOpts) string {
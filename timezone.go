@@ -7,17 +7,21 @@ import (
 	"time"
 )
 
-// TimeZone requests time zone information about a location.
+// TimeZone requests time zone information about a location, using the Client configured in ctx.
 //
 // See https://developers.google.com/maps/documentation/timezone/
 func TimeZone(ctx context.Context, ll LatLng, t time.Time, opts *TimeZoneOpts) (*TimeZoneResult, error) {
+	return fromContext(ctx).TimeZone(ctx, ll, t, opts)
+}
+
+// TimeZone requests time zone information about a location.
+//
+// See https://developers.google.com/maps/documentation/timezone/
+func (c *Client) TimeZone(ctx context.Context, ll LatLng, t time.Time, opts *TimeZoneOpts) (*TimeZoneResult, error) {
 	var r timeZoneResponse
-	if err := doDecode(ctx, baseURL+timezone(ll, t, opts), &r); err != nil {
+	if err := c.doDecode(ctx, ServiceTimeZone, timezone(ll, t, opts), &r); err != nil {
 		return nil, err
 	}
-	if r.Status != StatusOK {
-		return nil, APIError{r.Status, r.ErrorMessage}
-	}
 	return &r.TimeZoneResult, nil
 }
 
@@ -52,6 +56,8 @@ type timeZoneResponse struct {
 	TimeZoneResult
 }
 
+func (r timeZoneResponse) apiStatus() (string, string) { return r.Status, r.ErrorMessage }
+
 // TimeZoneResult describes information about the time zone at the requested location.
 type TimeZoneResult struct {
 	// DSTOffset is the offset for daylight-savings time in seconds.